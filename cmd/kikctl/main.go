@@ -0,0 +1,237 @@
+// Command kikctl administers a Kik bot from the command line, using the kik package.
+//
+//	kikctl [-username name] [-key key] <command> [arguments]
+//
+// The commands are:
+//
+//	config get                           print the bot's configuration as JSON
+//	config set < config.json             replace the configuration with the JSON read from stdin
+//	send -to user [-chat id] body...     send a text message
+//	user username                        print a user's profile as JSON
+//	code [-color n] [-o file] [data]     create a Kik code embedding data, and print its id and URL
+//	verify -signature sig < body         check the X-Kik-Signature of a webhook body read from stdin
+//	replay -url url < recording.jsonl    deliver the webhooks recorded by a kik.Recorder to the bot at url
+//
+// The credentials default to the KIK_BOT_USERNAME and KIK_API_KEY environment variables.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/r-kells/go-kik/kik"
+)
+
+// usageError is returned for invalid command lines, main exits with status 2 for them.
+type usageError string
+
+func (e usageError) Error() string { return string(e) }
+
+func main() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		cancel()
+	}()
+
+	err := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	var usage usageError
+	switch {
+	case errors.As(err, &usage):
+		fmt.Fprintf(os.Stderr, "kikctl: %v\nRun 'kikctl -h' for usage.\n", err)
+		os.Exit(2)
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "kikctl: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run executes the command line args, it is main without the process around it.
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("kikctl", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	username := flags.String("username", os.Getenv("KIK_BOT_USERNAME"), "the bot's username, defaults to $KIK_BOT_USERNAME")
+	apiKey := flags.String("key", os.Getenv("KIK_API_KEY"), "the bot's API key, defaults to $KIK_API_KEY")
+	baseUrl := flags.String("base-url", kik.DefaultBaseUrl, "the URL of the Kik API")
+	if err := flags.Parse(args); err != nil {
+		return usageError(err.Error())
+	}
+	if flags.NArg() == 0 {
+		return usageError("no command given")
+	}
+	if *username == "" || *apiKey == "" {
+		return usageError("the bot's username and API key are required, set -username and -key")
+	}
+
+	client, err := kik.NewClient(*username, *apiKey, kik.WithBaseUrl(*baseUrl))
+	if err != nil {
+		return err
+	}
+
+	command, args := flags.Arg(0), flags.Args()[1:]
+	switch command {
+	case "config":
+		return config(ctx, client, args, stdin, stdout)
+	case "send":
+		return send(ctx, client, args, stderr)
+	case "user":
+		return user(ctx, client, args, stdout)
+	case "code":
+		return code(ctx, client, args, stdout, stderr)
+	case "verify":
+		return verify(client, args, stdin, stdout, stderr)
+	case "replay":
+		return replay(ctx, args, stdin, stdout, stderr)
+	}
+	return usageError(fmt.Sprintf("unknown command %q", command))
+}
+
+func config(ctx context.Context, client *kik.Client, args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) != 1 {
+		return usageError("usage: config get | config set < config.json")
+	}
+	switch args[0] {
+	case "get":
+		c, err := client.GetConfiguration(ctx)
+		if err != nil {
+			return err
+		}
+		return printJSON(stdout, c)
+	case "set":
+		var c kik.Configuration
+		if err := json.NewDecoder(stdin).Decode(&c); err != nil {
+			return fmt.Errorf("error reading the configuration from stdin: %w", err)
+		}
+		if err := client.SetConfiguration(ctx, &c); err != nil {
+			return err
+		}
+		return printJSON(stdout, &c)
+	}
+	return usageError(fmt.Sprintf("unknown config command %q", args[0]))
+}
+
+func send(ctx context.Context, client *kik.Client, args []string, stderr io.Writer) error {
+	flags := flag.NewFlagSet("send", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	to := flags.String("to", "", "the username to send the message to")
+	chatId := flags.String("chat", "", "the chat to send the message in")
+	if err := flags.Parse(args); err != nil {
+		return usageError(err.Error())
+	}
+
+	m, err := kik.NewText(strings.Join(flags.Args(), " ")).To(*to).Chat(*chatId).Build()
+	if err != nil {
+		return usageError(err.Error())
+	}
+	return client.SendMessage(ctx, []kik.Message{m})
+}
+
+func user(ctx context.Context, client *kik.Client, args []string, stdout io.Writer) error {
+	if len(args) != 1 {
+		return usageError("usage: user username")
+	}
+	u, err := client.GetUser(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return printJSON(stdout, u)
+}
+
+func code(ctx context.Context, client *kik.Client, args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("code", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	color := flags.Int("color", int(kik.CodeKikBlue), "the color of the code, 0 to 15")
+	out := flags.String("o", "", "download the PNG image of the code to this file")
+	if err := flags.Parse(args); err != nil {
+		return usageError(err.Error())
+	}
+
+	c, err := client.CreateCode(ctx, &kik.ScanData{Data: strings.Join(flags.Args(), " ")})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, c.Id)
+	fmt.Fprintln(stdout, c.Url(kik.CodeColor(*color)))
+
+	if *out == "" {
+		return nil
+	}
+	png, err := client.DownloadCode(ctx, c, kik.CodeColor(*color))
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(*out, png, 0644)
+}
+
+func verify(client *kik.Client, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("verify", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	signature := flags.String("signature", "", "the X-Kik-Signature header of the webhook request")
+	if err := flags.Parse(args); err != nil {
+		return usageError(err.Error())
+	}
+	if *signature == "" {
+		return usageError("usage: verify -signature sig < body")
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(stdin, kik.MaxWebhookBodySize))
+	if err != nil {
+		return err
+	}
+	if !client.VerifySignature(*signature, body) {
+		return kik.InvalidSignatureError
+	}
+	fmt.Fprintln(stdout, "valid signature")
+	return nil
+}
+
+func replay(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("replay", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	url := flags.String("url", "", "the URL of the bot's webhook, the recorded path is ignored")
+	if err := flags.Parse(args); err != nil {
+		return usageError(err.Error())
+	}
+	if *url == "" {
+		return usageError("usage: replay -url url < recording.jsonl")
+	}
+
+	webhooks, err := kik.ReadRecording(stdin)
+	if err != nil {
+		return err
+	}
+	for i, w := range webhooks {
+		req, err := w.Request(ctx, *url)
+		if err != nil {
+			return err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return fmt.Errorf("replaying webhook %d: %s", i, resp.Status)
+		}
+	}
+	fmt.Fprintf(stdout, "replayed %d webhooks\n", len(webhooks))
+	return nil
+}
+
+func printJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/r-kells/go-kik/kik"
+	"github.com/r-kells/go-kik/kiktest"
+)
+
+// kikctl runs the command line args against server, and returns what it printed.
+func kikctl(server *kiktest.Server, stdin string, args ...string) (string, error) {
+	args = append([]string{"-username", server.BotUsername, "-key", server.ApiKey, "-base-url", server.URL + "/"}, args...)
+	var stdout, stderr bytes.Buffer
+	err := run(context.Background(), args, strings.NewReader(stdin), &stdout, &stderr)
+	return stdout.String(), err
+}
+
+func TestRun_Config(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+
+	if _, err := kikctl(server, `{"webhook": "https://example.com/incoming", "features": {}}`, "config", "set"); err != nil {
+		t.Fatalf("config set returned an error = %+v; expected no error", err)
+	}
+	out, err := kikctl(server, "", "config", "get")
+	if err != nil || !strings.Contains(out, `"webhook": "https://example.com/incoming"`) {
+		t.Errorf("config get = %q, %v; want the webhook that was set", out, err)
+	}
+}
+
+func TestRun_Send(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+
+	if _, err := kikctl(server, "", "send", "-to", "laura", "-chat", "c1", "Hello", "there"); err != nil {
+		t.Fatalf("send returned an error = %+v; expected no error", err)
+	}
+	if sent := server.Messages(); len(sent) != 1 || sent[0].Body != "Hello there" || sent[0].To != "laura" || sent[0].ChatId != "c1" {
+		t.Errorf("send sent %+v; want Hello there to laura in c1", sent)
+	}
+
+	var usage usageError
+	if _, err := kikctl(server, "", "send", "Hello"); !errors.As(err, &usage) {
+		t.Errorf("send without -to = %v; want a usage error", err)
+	}
+}
+
+func TestRun_User(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+	server.AddUser("laura", &kik.User{FirstName: "Laura"})
+
+	out, err := kikctl(server, "", "user", "laura")
+	if err != nil || !strings.Contains(out, `"FirstName": "Laura"`) {
+		t.Errorf("user laura = %q, %v; want the profile", out, err)
+	}
+}
+
+func TestRun_Code(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+
+	out, err := kikctl(server, "", "code", "-color", "3", "campaign")
+	if err != nil {
+		t.Fatalf("code returned an error = %+v; expected no error", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[1], "?c=3") {
+		t.Fatalf("code = %q; want the id and the URL of the code", out)
+	}
+	if data, ok := server.CodeData(lines[0]); !ok || data != "campaign" {
+		t.Errorf("CodeData(%s) = %s; want campaign", lines[0], data)
+	}
+}
+
+func TestRun_Verify(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+	body := `{"messages": []}`
+
+	if out, err := kikctl(server, body, "verify", "-signature", server.Sign([]byte(body))); err != nil || out != "valid signature\n" {
+		t.Errorf("verify = %q, %v; want a valid signature", out, err)
+	}
+	if _, err := kikctl(server, body, "verify", "-signature", "0000"); !errors.Is(err, kik.InvalidSignatureError) {
+		t.Errorf("verify with a wrong signature = %v; want InvalidSignatureError", err)
+	}
+}
+
+func TestRun_Usage(t *testing.T) {
+	var usage usageError
+	for _, args := range [][]string{nil, {"-username", "bot", "-key", "key"}, {"-username", "bot", "-key", "key", "frobnicate"}} {
+		err := run(context.Background(), args, strings.NewReader(""), &bytes.Buffer{}, &bytes.Buffer{})
+		if !errors.As(err, &usage) {
+			t.Errorf("run(%v) = %v; want a usage error", args, err)
+		}
+	}
+}
+
+func TestRun_Replay(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+
+	var got []string
+	bot := httptest.NewServer(kik.NewWebhookHandler(server.Client, func(ctx context.Context, m kik.Receive) error {
+		got = append(got, m.(*kik.TextMessageReceive).Body)
+		return nil
+	}))
+	defer bot.Close()
+
+	body := `{"messages": [{"type": "text", "from": "laura", "chatId": "c1", "body": "Hi"}]}`
+	line, _ := json.Marshal(kik.RecordedWebhook{Path: "/incoming", Signature: server.Sign([]byte(body)), Body: body})
+
+	out, err := kikctl(server, string(line)+"\n", "replay", "-url", bot.URL)
+	if err != nil || out != "replayed 1 webhooks\n" || len(got) != 1 || got[0] != "Hi" {
+		t.Errorf("replay = %q, %v, dispatched %v; want the recorded message dispatched", out, err, got)
+	}
+}
@@ -0,0 +1,39 @@
+package kik
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// API is the set of Client methods calling the Kik API or depending on its credentials.
+// Code taking an API instead of a *Client can be tested without network calls, see the kikmock package.
+type API interface {
+	SendMessage(ctx context.Context, messages []Message) error
+	SendIsTyping(ctx context.Context, to string, chatId string, typing bool) error
+	SendReadReceipt(ctx context.Context, to string, chatId string, messageIds []string) error
+	SendVideoFile(ctx context.Context, path string, upload Uploader, message VideoMessage) error
+	Reply(ctx context.Context, incoming Receive, messages ...Message) error
+	BroadcastMessage(ctx context.Context, messages []Message) error
+	BroadcastToUsers(ctx context.Context, usernames []string, template Message) ([]BroadcastResult, error)
+	EstimateSize(messages []Message) (int, error)
+
+	GetUser(ctx context.Context, username string) (*User, error)
+	GetUsers(ctx context.Context, usernames []string, concurrency int) (map[string]*User, map[string]error)
+	FetchProfilePic(ctx context.Context, user *User, ifModifiedSince time.Time) ([]byte, bool, error)
+	DownloadProfilePicture(ctx context.Context, user *User, cached *ProfilePicture) (*ProfilePicture, error)
+
+	GetConfiguration(ctx context.Context) (*Configuration, error)
+	SetConfiguration(ctx context.Context, c *Configuration) error
+
+	CreateCode(ctx context.Context, s *ScanData) (*Code, error)
+	DownloadCode(ctx context.Context, code *Code, color CodeColor) ([]byte, error)
+
+	VerifySignature(signature string, body []byte) bool
+	VerifyRequest(r *http.Request) ([]byte, error)
+	BatchVerify(payloads []SignedPayload) []bool
+
+	Do(ctx context.Context, method, path string, body, out interface{}) error
+}
+
+var _ API = (*Client)(nil)
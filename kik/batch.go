@@ -0,0 +1,209 @@
+package kik
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ChunkError is the error of a single request when messages were split across several requests.
+type ChunkError struct {
+	Offset   int       // The index of the first message of the request.
+	Count    int       // The number of messages in the request.
+	Messages []Message // The messages of the request.
+	Err      error
+}
+
+func (e *ChunkError) Error() string {
+	return fmt.Sprintf("messages %d-%d: %v", e.Offset, e.Offset+e.Count-1, e.Err)
+}
+
+func (e *ChunkError) Unwrap() error { return e.Err }
+
+// BatchError is returned when messages were split across several requests and some of them failed.
+// Messages of requests not listed were sent successfully.
+type BatchError struct {
+	Errors []*ChunkError // Ordered by Offset.
+}
+
+func (e *BatchError) Error() string {
+	errs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err.Error()
+	}
+	return fmt.Sprintf("%d of the batched requests failed: %s", len(e.Errors), strings.Join(errs, "; "))
+}
+
+// FailedMessage is a message that was not sent because its request failed, see BatchError.Failed.
+type FailedMessage struct {
+	Index   int // The index of the message in the messages that were sent.
+	Message Message
+	Err     error // The error of the request that carried the message.
+
+	// Rejected is set if Kik named the message as the reason it rejected the request,
+	// sending it again fails the same way. Other messages of the request were only
+	// not sent along with it and can be retried as they are.
+	Rejected bool
+	Reason   string // Kik's error message if Rejected.
+}
+
+// messageRef matches the references of Kik error messages to a message of the request, e.g. "messages[3].body".
+var messageRef = regexp.MustCompile(`messages\[(\d+)\]`)
+
+// Failed returns every message of the failed requests in order, to retry only those:
+//
+//	var batchErr *kik.BatchError
+//	if errors.As(err, &batchErr) {
+//		var retry []kik.Message
+//		for _, f := range batchErr.Failed() {
+//			if !f.Rejected {
+//				retry = append(retry, f.Message)
+//			}
+//		}
+//	}
+func (e *BatchError) Failed() []FailedMessage {
+	var failed []FailedMessage
+	for _, chunkErr := range e.Errors {
+		rejected := map[int]string{}
+		var apiErr *APIError
+		if errors.As(chunkErr.Err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest {
+			for _, ref := range messageRef.FindAllStringSubmatch(apiErr.Message, -1) {
+				i, _ := strconv.Atoi(ref[1])
+				rejected[i] = apiErr.Message
+			}
+		}
+		for i, m := range chunkErr.Messages {
+			reason, ok := rejected[i]
+			failed = append(failed, FailedMessage{
+				Index:    chunkErr.Offset + i,
+				Message:  m,
+				Err:      chunkErr.Err,
+				Rejected: ok,
+				Reason:   reason,
+			})
+		}
+	}
+	return failed
+}
+
+// chunk is a slice of messages sent in a single request.
+type chunk struct {
+	offset   int
+	messages []Message
+}
+
+// chunkMessages splits messages, in order, into chunks of at most max messages,
+// with at most perUser messages to the same recipient. A perUser of 0 means no limit.
+func chunkMessages(messages []Message, max, perUser int) []chunk {
+	var (
+		chunks  []chunk
+		current = chunk{}
+		users   = make(map[string]int)
+	)
+	for i, m := range messages {
+		to := m.header().To
+		if len(current.messages) == max || (perUser > 0 && users[to] == perUser) {
+			chunks = append(chunks, current)
+			current = chunk{offset: i}
+			users = make(map[string]int)
+		}
+		current.messages = append(current.messages, m)
+		users[to]++
+	}
+	if len(current.messages) > 0 {
+		chunks = append(chunks, current)
+	}
+	return chunks
+}
+
+// sendChunks sends each chunk as its own request, up to BatchConcurrency at a time.
+// A single chunk returns its error as is, otherwise failed chunks are collected into a BatchError.
+func (k *Client) sendChunks(ctx context.Context, urlStr string, chunks []chunk) error {
+	if len(chunks) == 1 {
+		return k.call(ctx, "POST", urlStr, Messages{chunks[0].messages}, nil)
+	}
+
+	concurrency := k.BatchConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	errs := make([]error, len(chunks))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, c := range chunks {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, c chunk) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = k.call(ctx, "POST", urlStr, Messages{c.messages}, nil)
+		}(i, c)
+	}
+	wg.Wait()
+
+	var batchErr BatchError
+	for i, err := range errs {
+		if err != nil {
+			batchErr.Errors = append(batchErr.Errors, &ChunkError{
+				Offset:   chunks[i].offset,
+				Count:    len(chunks[i].messages),
+				Messages: chunks[i].messages,
+				Err:      err,
+			})
+		}
+	}
+	if len(batchErr.Errors) > 0 {
+		return &batchErr
+	}
+	return nil
+}
+
+// BroadcastResult is the outcome of broadcasting to a single user.
+type BroadcastResult struct {
+	Username string
+	Err      error // nil if the message was sent.
+}
+
+// BroadcastToUsers broadcasts a copy of template to each of usernames, the To, ChatId and Id of template are replaced.
+// Requests are split and paced like BroadcastMessage. The results are in the order of usernames,
+// if any of them failed the error is returned as well.
+func (k *Client) BroadcastToUsers(ctx context.Context, usernames []string, template Message) ([]BroadcastResult, error) {
+	messages := make([]Message, len(usernames))
+	for i, username := range usernames {
+		messages[i] = withHeader(template, func(s *SendMessage) {
+			s.To = username
+			s.ChatId = ""
+			s.Id = "" // Every copy is a message of its own.
+		})
+	}
+
+	results := make([]BroadcastResult, len(usernames))
+	for i, username := range usernames {
+		results[i].Username = username
+	}
+
+	err := k.BroadcastMessage(ctx, messages)
+	if err == nil {
+		return results, nil
+	}
+
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) {
+		for i := range results {
+			results[i].Err = err
+		}
+		return results, err
+	}
+	for _, chunkErr := range batchErr.Errors {
+		for i := chunkErr.Offset; i < chunkErr.Offset+chunkErr.Count; i++ {
+			results[i].Err = chunkErr.Err
+		}
+	}
+	return results, err
+}
@@ -0,0 +1,263 @@
+package kik_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/r-kells/go-kik/kik"
+	"github.com/r-kells/go-kik/kiktest"
+)
+
+// recordBatches records the bodies of every request sent to url, failing those containing a message with body fail.
+func recordBatches(mux *http.ServeMux, url string) func() [][]string {
+	var (
+		mu      sync.Mutex
+		batches [][]string
+	)
+	mux.HandleFunc(url, func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Messages []struct{ To, Body string } `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&payload)
+
+		var bodies []string
+		for _, m := range payload.Messages {
+			bodies = append(bodies, m.Body)
+			if m.Body == "fail" {
+				w.WriteHeader(http.StatusBadRequest)
+			}
+		}
+		mu.Lock()
+		batches = append(batches, bodies)
+		mu.Unlock()
+	})
+	return func() [][]string {
+		mu.Lock()
+		defer mu.Unlock()
+		return batches
+	}
+}
+
+func textMessages(n int, to func(i int) string) []kik.Message {
+	var messages []kik.Message
+	for i := 0; i < n; i++ {
+		messages = append(messages, kik.TextMessage{
+			SendMessage: kik.SendMessage{To: to(i), Type: "text"},
+			Body:        fmt.Sprint(i),
+		})
+	}
+	return messages
+}
+
+func TestSendMessage_SplitsLargeBatches(t *testing.T) {
+	client, mux, teardown := kiktest.TestClient(t)
+	defer teardown()
+	batches := recordBatches(mux, kik.SendMessageUrl)
+
+	messages := textMessages(60, func(i int) string { return fmt.Sprintf("user%d", i) })
+	if err := client.SendMessage(context.Background(), messages); err != nil {
+		t.Fatalf("SendMessage() returned an error = %+v; expected no error", err)
+	}
+
+	got := batches()
+	if len(got) != 3 || len(got[0]) != 25 || len(got[1]) != 25 || len(got[2]) != 10 {
+		t.Errorf("sent batches of %v; want 25, 25 and 10 messages", got)
+	}
+	if got[1][0] != "25" {
+		t.Errorf("second batch starts with message %s; want messages in order", got[1][0])
+	}
+}
+
+func TestSendMessage_SplitsPerUser(t *testing.T) {
+	client, mux, teardown := kiktest.TestClient(t)
+	defer teardown()
+	batches := recordBatches(mux, kik.SendMessageUrl)
+
+	messages := textMessages(7, func(i int) string { return username })
+	if err := client.SendMessage(context.Background(), messages); err != nil {
+		t.Fatalf("SendMessage() returned an error = %+v; expected no error", err)
+	}
+
+	if got := batches(); len(got) != 2 || len(got[0]) != kik.MaxMessagesPerUser || len(got[1]) != 2 {
+		t.Errorf("sent batches of %v; want 5 and 2 messages", got)
+	}
+}
+
+func TestSendMessage_BatchError(t *testing.T) {
+	client, mux, teardown := kiktest.TestClient(t)
+	defer teardown()
+	client.BatchConcurrency = 3
+	batches := recordBatches(mux, kik.SendMessageUrl)
+
+	messages := textMessages(60, func(i int) string { return fmt.Sprintf("user%d", i) })
+	messages[30] = kik.TextMessage{SendMessage: kik.SendMessage{To: "user30", Type: "text"}, Body: "fail"}
+	err := client.SendMessage(context.Background(), messages)
+
+	var batchErr *kik.BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("Expected a *kik.BatchError, got %v", err)
+	}
+	if len(batchErr.Errors) != 1 || batchErr.Errors[0].Offset != 25 || batchErr.Errors[0].Count != 25 {
+		t.Errorf("BatchError = %v; want only messages 25-49 to fail", batchErr)
+	}
+	if !errors.Is(batchErr.Errors[0], kik.HttpError) {
+		t.Errorf("ChunkError = %v; want the HttpError of the request", batchErr.Errors[0])
+	}
+	if len(batches()) != 3 {
+		t.Errorf("sent %d batches; want all 3 to be attempted", len(batches()))
+	}
+}
+
+func TestBatchError_Failed(t *testing.T) {
+	client, mux, teardown := kiktest.TestClient(t)
+	defer teardown()
+	mux.HandleFunc(kik.SendMessageUrl, func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Messages []struct{ Body string } `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&payload)
+		for i, m := range payload.Messages {
+			if m.Body == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprintf(w, `{"error": "BadRequest", "message": "messages[%d].body: may not be empty"}`, i)
+				return
+			}
+		}
+	})
+
+	messages := textMessages(30, func(i int) string { return fmt.Sprintf("user%d", i) })
+	messages[27] = kik.TextMessage{SendMessage: kik.SendMessage{To: "user27", Type: "text"}}
+	err := client.SendMessage(context.Background(), messages)
+
+	var batchErr *kik.BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("Expected a *kik.BatchError, got %v", err)
+	}
+	failed := batchErr.Failed()
+	if len(failed) != 5 || failed[0].Index != 25 || failed[0].Message.(kik.TextMessage).Body != "25" {
+		t.Fatalf("Failed() = %+v; want messages 25-29", failed)
+	}
+	for _, f := range failed {
+		if f.Rejected != (f.Index == 27) || !errors.Is(f.Err, kik.HttpError) {
+			t.Errorf("Failed() message %d = %+v; want only message 27 rejected", f.Index, f)
+		}
+	}
+	if failed[2].Reason != "messages[2].body: may not be empty" {
+		t.Errorf("Failed()[2].Reason = %q; want Kik's error message", failed[2].Reason)
+	}
+}
+
+func TestBroadcastMessage_SplitsLargeBatches(t *testing.T) {
+	client, mux, teardown := kiktest.TestClient(t)
+	defer teardown()
+	batches := recordBatches(mux, kik.BroadcastUrl)
+
+	messages := textMessages(30, func(i int) string { return username })
+	if err := client.BroadcastMessage(context.Background(), messages); err != nil {
+		t.Fatalf("BroadcastMessage() returned an error = %+v; expected no error", err)
+	}
+
+	if got := batches(); len(got) != 2 || len(got[0]) != kik.MaxBroadcastMessages {
+		t.Errorf("sent batches of %v; want 25 and 5 messages", got)
+	}
+}
+
+func TestBroadcastToUsers_PerRecipientResults(t *testing.T) {
+	client, mux, teardown := kiktest.TestClient(t)
+	defer teardown()
+
+	var (
+		mu  sync.Mutex
+		got []string
+	)
+	mux.HandleFunc(kik.BroadcastUrl, func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Messages []struct{ To, ChatId, Body string } `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&payload)
+		for _, m := range payload.Messages {
+			if m.To == "user30" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if m.ChatId != "" || m.Body != "News" {
+				t.Errorf("broadcast %+v; want the template without its chat id", m)
+			}
+		}
+		mu.Lock()
+		for _, m := range payload.Messages {
+			got = append(got, m.To)
+		}
+		mu.Unlock()
+	})
+
+	var usernames []string
+	for i := 0; i < 60; i++ {
+		usernames = append(usernames, fmt.Sprintf("user%d", i))
+	}
+	template := kik.TextMessage{SendMessage: kik.SendMessage{Type: "text", ChatId: "c1"}, Body: "News"}
+
+	results, err := client.BroadcastToUsers(context.Background(), usernames, template)
+
+	var batchErr *kik.BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("BroadcastToUsers() = %v; want a *kik.BatchError", err)
+	}
+	if len(results) != len(usernames) || len(got) != 35 {
+		t.Fatalf("BroadcastToUsers() returned %d results and sent to %d users; want 60 and 35", len(results), len(got))
+	}
+	for i, result := range results {
+		failed := i >= 25 && i < 50
+		if result.Username != usernames[i] || (result.Err != nil) != failed {
+			t.Errorf("results[%d] = %+v; want %s failed = %v", i, result, usernames[i], failed)
+		}
+	}
+}
+
+func TestBroadcastToUsers_PointerTemplate(t *testing.T) {
+	client, mux, teardown := kiktest.TestClient(t)
+	defer teardown()
+
+	var got []string
+	mux.HandleFunc(kik.BroadcastUrl, func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Messages []struct{ To string } `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&payload)
+		for _, m := range payload.Messages {
+			got = append(got, m.To)
+		}
+	})
+
+	template := &kik.TextMessage{SendMessage: kik.SendMessage{To: "template", Type: "text"}, Body: "News"}
+	if _, err := client.BroadcastToUsers(context.Background(), []string{"laura", username}, template); err != nil {
+		t.Fatalf("BroadcastToUsers() returned an error = %+v; expected no error", err)
+	}
+	if want := []string{"laura", username}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("BroadcastToUsers() sent to %v; want %v", got, want)
+	}
+	if template.To != "template" {
+		t.Errorf("BroadcastToUsers() changed the template to %s", template.To)
+	}
+}
+
+func TestBroadcastToUsers_HappyPath(t *testing.T) {
+	client, mux, teardown := kiktest.TestClient(t)
+	defer teardown()
+	batches := recordBatches(mux, kik.BroadcastUrl)
+
+	template := kik.TextMessage{SendMessage: kik.SendMessage{Type: "text"}, Body: "News"}
+	results, err := client.BroadcastToUsers(context.Background(), []string{"laura", username}, template)
+
+	if err != nil {
+		t.Fatalf("BroadcastToUsers() returned an error = %+v; expected no error", err)
+	}
+	if len(batches()) != 1 || len(results) != 2 || results[0].Err != nil || results[1].Err != nil {
+		t.Errorf("BroadcastToUsers() = %+v in %d requests; want both sent in one request", results, len(batches()))
+	}
+}
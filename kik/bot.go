@@ -0,0 +1,319 @@
+package kik
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// BotHandler handles an incoming message for a Bot.
+// The returned messages are sent back to the chat the message came from,
+// messages without a recipient are addressed to its sender.
+type BotHandler func(ctx context.Context, m Receive) ([]Message, error)
+
+// Bot routes incoming messages to handlers registered by message type and text pattern,
+// and sends their replies. It is an http.Handler for the webhook configured with Kik.
+type Bot struct {
+	Client *Client
+
+	// OnError is called, if set, for invalid webhook requests and errors from handlers or sending replies.
+	OnError func(r *http.Request, err error)
+
+	// Sessions, if set, loads the session of each message's conversation for its handler, see SessionFromContext.
+	Sessions *Sessions
+
+	mu         sync.RWMutex
+	text       []textRoute
+	handlers   map[string]BotHandler // by message type.
+	fallback   BotHandler
+	messages   *subscription // The channel of Messages, if it is the fallback.
+	middleware []Middleware
+	chain      BotHandler // middleware around dispatch, nil without middleware.
+}
+
+type textRoute struct {
+	pattern string
+	handler BotHandler
+}
+
+// NewBot returns a Bot sending replies with k.
+func NewBot(k *Client) *Bot {
+	return &Bot{Client: k, handlers: make(map[string]BotHandler)}
+}
+
+// HandleText registers h for text messages matching pattern.
+// A pattern matches a body equal to it, or starting with it followed by a space, e.g. "/start" matches "/start now".
+// Matching ignores case and surrounding whitespace, an empty pattern matches any text.
+// Patterns are tried in the order they were registered.
+func (b *Bot) HandleText(pattern string, h func(ctx context.Context, m *TextMessageReceive) ([]Message, error)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.text = append(b.text, textRoute{
+		pattern: strings.ToLower(strings.TrimSpace(pattern)),
+		handler: func(ctx context.Context, m Receive) ([]Message, error) {
+			typed, ok := m.(*TextMessageReceive)
+			if !ok {
+				return nil, unexpectedType(m)
+			}
+			return h(ctx, typed)
+		},
+	})
+}
+
+// HandlePicture registers h for picture messages.
+func (b *Bot) HandlePicture(h func(ctx context.Context, m *PictureMessageReceive) ([]Message, error)) {
+	b.Handle("picture", func(ctx context.Context, m Receive) ([]Message, error) {
+		typed, ok := m.(*PictureMessageReceive)
+		if !ok {
+			return nil, unexpectedType(m)
+		}
+		return h(ctx, typed)
+	})
+}
+
+// HandleSticker registers h for sticker messages.
+func (b *Bot) HandleSticker(h func(ctx context.Context, m *StickerMessageReceive) ([]Message, error)) {
+	b.Handle("sticker", func(ctx context.Context, m Receive) ([]Message, error) {
+		typed, ok := m.(*StickerMessageReceive)
+		if !ok {
+			return nil, unexpectedType(m)
+		}
+		return h(ctx, typed)
+	})
+}
+
+// HandleFriendPicker registers h for the friends users picked with a friend picker response, see Keyboard.WithFriendPicker.
+func (b *Bot) HandleFriendPicker(h func(ctx context.Context, m *FriendPickerReceive) ([]Message, error)) {
+	b.Handle("friend-picker", func(ctx context.Context, m Receive) ([]Message, error) {
+		typed, ok := m.(*FriendPickerReceive)
+		if !ok {
+			return nil, unexpectedType(m)
+		}
+		return h(ctx, typed)
+	})
+}
+
+// HandleStartChatting registers h for users starting a chat with the bot.
+func (b *Bot) HandleStartChatting(h func(ctx context.Context, m *StartChattingReceive) ([]Message, error)) {
+	b.Handle("start-chatting", func(ctx context.Context, m Receive) ([]Message, error) {
+		typed, ok := m.(*StartChattingReceive)
+		if !ok {
+			return nil, unexpectedType(m)
+		}
+		return h(ctx, typed)
+	})
+}
+
+// Handle registers h for every message of messageType, e.g. "scan-data".
+// For text messages it is only called if no HandleText pattern matches.
+func (b *Bot) Handle(messageType string, h BotHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[messageType] = h
+}
+
+// HandleDefault registers h for messages no other handler matches, they are ignored otherwise.
+func (b *Bot) HandleDefault(h BotHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.fallback, b.messages = h, nil
+}
+
+// Middleware wraps the handling of every incoming message of a Bot, e.g. for logging, allow lists or metrics.
+// It runs before messages are routed, so it sees messages without a handler too, and may return without calling next.
+type Middleware func(next BotHandler) BotHandler
+
+// Use adds middleware around message handling, the first one added is the outermost.
+// The chain is composed here, not for every message, so Use is meant to be called while setting up the Bot.
+func (b *Bot) Use(middleware ...Middleware) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.middleware = append(b.middleware, middleware...)
+
+	chain := BotHandler(b.dispatch)
+	for i := len(b.middleware) - 1; i >= 0; i-- {
+		chain = b.middleware[i](chain)
+	}
+	b.chain = chain
+}
+
+// HandleMessage dispatches m through the middleware to its handler and sends the replies.
+// The session, if the Bot has Sessions, is saved after the replies were sent.
+// It is the MessageHandler the Bot serves webhooks with.
+func (b *Bot) HandleMessage(ctx context.Context, m Receive) error {
+	m = receivePointer(m)
+	b.mu.RLock()
+	chain := b.chain
+	b.mu.RUnlock()
+	if chain == nil {
+		if b.route(m) == nil {
+			return nil
+		}
+		chain = b.dispatch
+	}
+
+	var (
+		session *Session
+		stored  bool
+	)
+	if b.Sessions != nil {
+		var err error
+		if session, err = b.Sessions.Load(ctx, m); err != nil {
+			return err
+		}
+		stored = len(session.Values) > 0
+		ctx = context.WithValue(ctx, sessionContextKey{}, session)
+	}
+
+	replies, err := chain(ctx, m)
+	if err != nil {
+		return err
+	}
+
+	if len(replies) > 0 {
+		from := m.header()
+		for i, reply := range replies {
+			if reply.header().To == "" {
+				replies[i] = addressed(reply, from.From, from.ChatId)
+			}
+		}
+		if err := b.Client.SendMessage(ctx, replies); err != nil {
+			return err
+		}
+	}
+
+	// Sessions that were and still are empty need not be deleted again.
+	if session != nil && (stored || len(session.Values) > 0) {
+		return b.Sessions.Save(ctx, session)
+	}
+	return nil
+}
+
+// dispatch calls the handler m is routed to, messages without one are ignored.
+func (b *Bot) dispatch(ctx context.Context, m Receive) ([]Message, error) {
+	m = receivePointer(m) // Middleware may pass on a message of its own.
+	h := b.route(m)
+	if h == nil {
+		return nil, nil
+	}
+	return h(ctx, m)
+}
+
+func (b *Bot) route(m Receive) BotHandler {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if text, ok := m.(*TextMessageReceive); ok {
+		body := strings.ToLower(strings.TrimSpace(text.Body))
+		for _, r := range b.text {
+			if r.pattern == "" || body == r.pattern || strings.HasPrefix(body, r.pattern+" ") {
+				return r.handler
+			}
+		}
+	}
+	if h, ok := b.handlers[m.header().Type]; ok {
+		return h
+	}
+	return b.fallback
+}
+
+// receivePointer returns a pointer to a copy of m if m is a value, e.g. a TextMessageReceive,
+// as the typed handlers get pointers like the ones ParseMessages returns.
+func receivePointer(m Receive) Receive {
+	v := reflect.ValueOf(m)
+	if v.Kind() != reflect.Struct {
+		return m
+	}
+	p := reflect.New(v.Type())
+	p.Elem().Set(v)
+	if r, ok := p.Interface().(Receive); ok {
+		return r
+	}
+	return m
+}
+
+// unexpectedType is the error of a typed handler getting a message of another Go type, e.g. from Handle.
+func unexpectedType(m Receive) error {
+	return fmt.Errorf("%w: %T for %q", NotMessageTypeError, m, m.header().Type)
+}
+
+func (b *Bot) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	wh := &WebhookHandler{Client: b.Client, Handler: b.HandleMessage, OnError: b.OnError}
+	wh.ServeHTTP(w, r)
+}
+
+// ListenAndServe serves the bot's webhook on every path of addr, see http.ListenAndServe.
+// Use a Server for health checks, TLS and graceful shutdown.
+func (b *Bot) ListenAndServe(addr string) error {
+	return http.ListenAndServe(addr, b)
+}
+
+// addressed returns a copy of m sent to the user to in chatId.
+func addressed(m Message, to, chatId string) Message {
+	return withHeader(m, func(s *SendMessage) {
+		s.To = to
+		s.ChatId = chatId
+	})
+}
+
+// withHeader returns a copy of m with its header modified by f, a pointer to a copy if m is a pointer.
+// Message types of other packages, which embed a SendMessage or a Message, are copied by reflection.
+func withHeader(m Message, f func(s *SendMessage)) Message {
+	switch m := m.(type) {
+	case TextMessage:
+		f(&m.SendMessage)
+		return m
+	case PictureMessage:
+		f(&m.SendMessage)
+		return m
+	case LinkMessage:
+		f(&m.SendMessage)
+		return m
+	case VideoMessage:
+		f(&m.SendMessage)
+		return m
+	case IsTypingMessage:
+		f(&m.SendMessage)
+		return m
+	case ReadReceiptMessage:
+		f(&m.SendMessage)
+		return m
+	}
+
+	v := reflect.ValueOf(m)
+	isPtr := v.Kind() == reflect.Ptr
+	if isPtr {
+		v = v.Elem()
+	}
+	c := reflect.New(v.Type()).Elem()
+	c.Set(v)
+	setHeader(c, f)
+	if isPtr {
+		return c.Addr().Interface().(Message)
+	}
+	return c.Interface().(Message)
+}
+
+var sendMessageType = reflect.TypeOf(SendMessage{})
+
+// setHeader applies f to the SendMessage embedded in the struct v, directly, through embedded structs
+// or through an embedded Message. Every Message embeds one of them, as that is how it gets the methods of a Message.
+func setHeader(v reflect.Value, f func(s *SendMessage)) {
+	if field := v.FieldByName("SendMessage"); field.IsValid() && field.Type() == sendMessageType {
+		f(field.Addr().Interface().(*SendMessage))
+		return
+	}
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if !v.Type().Field(i).Anonymous || field.Kind() != reflect.Interface || field.IsNil() || !field.CanSet() {
+			continue
+		}
+		if embedded, ok := field.Interface().(Message); ok {
+			field.Set(reflect.ValueOf(withHeader(embedded, f)))
+			return
+		}
+	}
+}
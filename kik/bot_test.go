@@ -0,0 +1,211 @@
+package kik_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/r-kells/go-kik/kik"
+	"github.com/r-kells/go-kik/kiktest"
+)
+
+func textFrom(from, chatId, body string) *kik.TextMessageReceive {
+	return &kik.TextMessageReceive{
+		ReceiveMessage: kik.ReceiveMessage{Type: "text", From: from, ChatId: chatId},
+		Body:           body,
+	}
+}
+
+func reply(body string) ([]kik.Message, error) {
+	return []kik.Message{kik.TextMessage{SendMessage: kik.SendMessage{Type: "text"}, Body: body}}, nil
+}
+
+func TestBot_RoutesText(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+
+	bot := kik.NewBot(server.Client)
+	bot.HandleText("/start", func(ctx context.Context, m *kik.TextMessageReceive) ([]kik.Message, error) {
+		return reply("started")
+	})
+	bot.HandleText("", func(ctx context.Context, m *kik.TextMessageReceive) ([]kik.Message, error) {
+		return reply("echo " + m.Body)
+	})
+
+	tests := []struct {
+		body string
+		want string
+	}{
+		{"/start", "started"},
+		{" /START now", "started"},
+		{"/started", "echo /started"},
+		{"Hi", "echo Hi"},
+	}
+	for _, test := range tests {
+		server.Reset()
+		if err := bot.HandleMessage(context.Background(), textFrom("laura", "c1", test.body)); err != nil {
+			t.Fatalf("HandleMessage(%s) returned an error = %+v; expected no error", test.body, err)
+		}
+
+		sent := server.Messages()
+		if len(sent) != 1 || sent[0].Body != test.want || sent[0].To != "laura" || sent[0].ChatId != "c1" {
+			t.Errorf("HandleMessage(%s) sent %+v; want %s to laura in c1", test.body, sent, test.want)
+		}
+	}
+}
+
+func TestBot_RoutesByType(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+
+	bot := kik.NewBot(server.Client)
+	bot.HandleStartChatting(func(ctx context.Context, m *kik.StartChattingReceive) ([]kik.Message, error) {
+		return reply("welcome")
+	})
+	bot.HandlePicture(func(ctx context.Context, m *kik.PictureMessageReceive) ([]kik.Message, error) {
+		return reply("nice picture")
+	})
+	bot.HandleDefault(func(ctx context.Context, m kik.Receive) ([]kik.Message, error) {
+		return reply("unsupported")
+	})
+
+	rec := server.Webhook(bot,
+		kik.StartChattingReceive{ReceiveMessage: kik.ReceiveMessage{Type: "start-chatting", From: "laura", ChatId: "c1"}},
+		kik.PictureMessageReceive{ReceiveMessage: kik.ReceiveMessage{Type: "picture", From: "laura", ChatId: "c1"}},
+		kik.TextMessageReceive{ReceiveMessage: kik.ReceiveMessage{Type: "text", From: "laura", ChatId: "c1"}, Body: "Hi"},
+	)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("ServeHTTP() status = %d; want %d", rec.Code, http.StatusOK)
+	}
+	var got []string
+	for _, m := range server.Messages() {
+		got = append(got, m.Body)
+	}
+	if len(got) != 3 || got[0] != "welcome" || got[1] != "nice picture" || got[2] != "unsupported" {
+		t.Errorf("replies = %v; want welcome, nice picture, unsupported", got)
+	}
+}
+
+func TestBot_KeepsRecipient(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+
+	bot := kik.NewBot(server.Client)
+	bot.HandleText("", func(ctx context.Context, m *kik.TextMessageReceive) ([]kik.Message, error) {
+		return []kik.Message{kik.TextMessage{SendMessage: kik.SendMessage{To: "moderator", Type: "text"}, Body: m.Body}}, nil
+	})
+
+	if err := bot.HandleMessage(context.Background(), textFrom("laura", "c1", "Hi")); err != nil {
+		t.Fatalf("HandleMessage() returned an error = %+v; expected no error", err)
+	}
+	if sent := server.Messages(); len(sent) != 1 || sent[0].To != "moderator" {
+		t.Errorf("HandleMessage() sent %+v; want it to keep the recipient", sent)
+	}
+}
+
+func TestBot_ValueMessage(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+
+	bot := kik.NewBot(server.Client)
+	bot.HandleText("", func(ctx context.Context, m *kik.TextMessageReceive) ([]kik.Message, error) {
+		return reply("echo " + m.Body)
+	})
+	bot.HandlePicture(func(ctx context.Context, m *kik.PictureMessageReceive) ([]kik.Message, error) {
+		return reply("picture")
+	})
+	bot.Handle("sticker", func(ctx context.Context, m kik.Receive) ([]kik.Message, error) {
+		return reply("sticker")
+	})
+
+	if err := bot.HandleMessage(context.Background(), *textFrom("laura", "c1", "Hi")); err != nil {
+		t.Fatalf("HandleMessage(value) returned an error = %+v; expected no error", err)
+	}
+	if sent := server.Messages(); len(sent) != 1 || sent[0].Body != "echo Hi" {
+		t.Errorf("HandleMessage(value) sent %+v; want echo Hi", sent)
+	}
+
+	// A message of another Go type routed to a typed handler.
+	wrong := &kik.StickerMessageReceive{ReceiveMessage: kik.ReceiveMessage{Type: "picture", From: "laura", ChatId: "c1"}}
+	if err := bot.HandleMessage(context.Background(), wrong); !errors.Is(err, kik.NotMessageTypeError) {
+		t.Errorf("HandleMessage(%T) = %v; want a NotMessageTypeError", wrong, err)
+	}
+}
+
+func TestBot_Unhandled(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+
+	bot := kik.NewBot(server.Client)
+	if err := bot.HandleMessage(context.Background(), textFrom("laura", "c1", "Hi")); err != nil {
+		t.Errorf("HandleMessage() returned an error = %+v; expected no error", err)
+	}
+	if sent := server.Messages(); len(sent) != 0 {
+		t.Errorf("HandleMessage() sent %+v; want no replies", sent)
+	}
+}
+
+func TestBot_HandlerError(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+
+	wantErr := errors.New("handler failed")
+	bot := kik.NewBot(server.Client)
+	bot.HandleText("", func(ctx context.Context, m *kik.TextMessageReceive) ([]kik.Message, error) {
+		return nil, wantErr
+	})
+	var gotErr error
+	bot.OnError = func(r *http.Request, err error) { gotErr = err }
+
+	rec := server.Webhook(bot, textFrom("laura", "c1", "Hi"))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("ServeHTTP() status = %d; want %d", rec.Code, http.StatusInternalServerError)
+	}
+	if gotErr != wantErr {
+		t.Errorf("OnError got %v; want %v", gotErr, wantErr)
+	}
+}
+
+func TestBot_Middleware(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+
+	bot := kik.NewBot(server.Client)
+	bot.HandleText("", func(ctx context.Context, m *kik.TextMessageReceive) ([]kik.Message, error) {
+		return reply("echo " + m.Body)
+	})
+
+	var trace []string
+	logging := func(next kik.BotHandler) kik.BotHandler {
+		return func(ctx context.Context, m kik.Receive) ([]kik.Message, error) {
+			trace = append(trace, "log")
+			return next(ctx, m)
+		}
+	}
+	allowList := func(next kik.BotHandler) kik.BotHandler {
+		return func(ctx context.Context, m kik.Receive) ([]kik.Message, error) {
+			trace = append(trace, "allow")
+			if m.(*kik.TextMessageReceive).From != "laura" {
+				return nil, nil
+			}
+			return next(ctx, m)
+		}
+	}
+	bot.Use(logging, allowList)
+
+	for _, from := range []string{"laura", "spammer"} {
+		if err := bot.HandleMessage(context.Background(), textFrom(from, "c1", "Hi")); err != nil {
+			t.Fatalf("HandleMessage() returned an error = %+v; expected no error", err)
+		}
+	}
+
+	if sent := server.Messages(); len(sent) != 1 || sent[0].To != "laura" {
+		t.Errorf("HandleMessage() sent %+v; want only a reply to laura", sent)
+	}
+	if len(trace) != 4 || trace[0] != "log" || trace[1] != "allow" {
+		t.Errorf("middleware ran as %v; want log then allow for each message", trace)
+	}
+}
@@ -0,0 +1,186 @@
+package kik
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// BreakerState is the state of the circuit of a single endpoint in a CircuitBreaker.
+type BreakerState int
+
+const (
+	BreakerClosed   BreakerState = iota // Requests are sent.
+	BreakerOpen                         // Requests fail fast with a CircuitOpenError.
+	BreakerHalfOpen                     // A single probe request is sent to check whether the endpoint recovered.
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("BreakerState(%d)", int(s))
+}
+
+const (
+	// DefaultBreakerThreshold is the number of consecutive failures opening a circuit, if Threshold is not set.
+	DefaultBreakerThreshold = 5
+	// DefaultBreakerCooldown is how long a circuit stays open before probing, if Cooldown is not set.
+	DefaultBreakerCooldown = 30 * time.Second
+)
+
+// CircuitBreaker stops sending requests to an endpoint of the Kik API that keeps failing, see WithCircuitBreaker.
+// After Threshold consecutive failures the circuit of the endpoint opens and requests fail fast with
+// a CircuitOpenError, without waiting for timeouts. After Cooldown a single request probes the endpoint,
+// closing the circuit if it succeeds or opening it again if it fails.
+//
+// Requests failing without a response, or with a 5xx status, are failures. Other error statuses,
+// including 429 Too Many Requests, show the API is up and count as successes.
+// Each attempt counts, so retries of a RetryPolicy may open the circuit.
+type CircuitBreaker struct {
+	Threshold int           // Defaults to DefaultBreakerThreshold.
+	Cooldown  time.Duration // Defaults to DefaultBreakerCooldown.
+
+	// OnStateChange is called, if set, when the circuit of an endpoint changes state, e.g. to alert while it is open.
+	// It is called with the CircuitBreaker locked, so it must not call its methods.
+	OnStateChange func(endpoint string, from, to BreakerState)
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+type circuit struct {
+	state    BreakerState
+	failures int       // Consecutive failures while closed.
+	openedAt time.Time // When the circuit last opened.
+	probing  bool      // Whether the probe of a half-open circuit is in flight.
+}
+
+// NewCircuitBreaker returns a CircuitBreaker opening after threshold consecutive failures, for cooldown.
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{Threshold: threshold, Cooldown: cooldown}
+}
+
+// WithCircuitBreaker makes the Client fail fast on endpoints that keep failing, see CircuitBreaker.
+func WithCircuitBreaker(b *CircuitBreaker) Option {
+	return func(k *Client) error {
+		k.Breaker = b
+		return nil
+	}
+}
+
+// State returns the state of the circuit of endpoint, e.g. EndpointSend.
+func (b *CircuitBreaker) State(endpoint string) BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.circuit(endpoint)
+	if c.state == BreakerOpen && !time.Now().Before(c.openedAt.Add(b.cooldown())) {
+		return BreakerHalfOpen
+	}
+	return c.state
+}
+
+// allow returns a CircuitOpenError if a request to endpoint must not be sent.
+func (b *CircuitBreaker) allow(endpoint string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.circuit(endpoint)
+	if c.state == BreakerOpen && !time.Now().Before(c.openedAt.Add(b.cooldown())) {
+		b.transition(endpoint, c, BreakerHalfOpen)
+	}
+	switch {
+	case c.state == BreakerOpen:
+		return fmt.Errorf("%w: %s", CircuitOpenError, endpoint)
+	case c.state == BreakerHalfOpen && c.probing:
+		return fmt.Errorf("%w: %s is being probed", CircuitOpenError, endpoint)
+	case c.state == BreakerHalfOpen:
+		c.probing = true
+	}
+	return nil
+}
+
+// record updates the circuit of endpoint with the outcome of a request that allow let through.
+func (b *CircuitBreaker) record(ctx context.Context, endpoint string, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.circuit(endpoint)
+	probe := c.probing
+	c.probing = false
+	if err != nil && ctx.Err() != nil {
+		return // Cancelled by the caller, that says nothing about the API.
+	}
+
+	if !breakerFailure(err) {
+		c.failures = 0
+		if c.state != BreakerClosed {
+			b.transition(endpoint, c, BreakerClosed)
+		}
+		return
+	}
+
+	c.failures++
+	if probe || c.failures >= b.threshold() {
+		c.openedAt = time.Now()
+		if c.state != BreakerOpen {
+			b.transition(endpoint, c, BreakerOpen)
+		}
+	}
+}
+
+// breakerFailure reports whether err shows the API is unavailable.
+func breakerFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= http.StatusInternalServerError
+	}
+	return true
+}
+
+// transition moves c to state, b.mu must be held.
+func (b *CircuitBreaker) transition(endpoint string, c *circuit, state BreakerState) {
+	from := c.state
+	c.state = state
+	if b.OnStateChange != nil {
+		b.OnStateChange(endpoint, from, state)
+	}
+}
+
+// circuit returns the circuit of endpoint, b.mu must be held.
+func (b *CircuitBreaker) circuit(endpoint string) *circuit {
+	if b.circuits == nil {
+		b.circuits = make(map[string]*circuit)
+	}
+	c, ok := b.circuits[endpoint]
+	if !ok {
+		c = &circuit{}
+		b.circuits[endpoint] = c
+	}
+	return c
+}
+
+func (b *CircuitBreaker) threshold() int {
+	if b.Threshold <= 0 {
+		return DefaultBreakerThreshold
+	}
+	return b.Threshold
+}
+
+func (b *CircuitBreaker) cooldown() time.Duration {
+	if b.Cooldown <= 0 {
+		return DefaultBreakerCooldown
+	}
+	return b.Cooldown
+}
@@ -0,0 +1,71 @@
+package kik_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/r-kells/go-kik/kik"
+	"github.com/r-kells/go-kik/kiktest"
+)
+
+func TestCircuitBreaker_OpensAndRecovers(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+	server.AddUser(username, &kik.User{FirstName: "Ryan"})
+	server.Fail(kik.GetUserUrl, http.StatusServiceUnavailable, 3)
+
+	var changes []string
+	breaker := kik.NewCircuitBreaker(2, 20*time.Millisecond)
+	breaker.OnStateChange = func(endpoint string, from, to kik.BreakerState) {
+		changes = append(changes, fmt.Sprintf("%s %v->%v", endpoint, from, to))
+	}
+	server.Client.Breaker = breaker
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := server.Client.GetUser(ctx, username); errors.Is(err, kik.CircuitOpenError) {
+			t.Fatalf("GetUser() attempt %d = %v; want the request to be sent", i, err)
+		}
+	}
+	if _, err := server.Client.GetUser(ctx, username); !errors.Is(err, kik.CircuitOpenError) {
+		t.Errorf("GetUser() with an open circuit = %v; want CircuitOpenError", err)
+	}
+	if err := server.Client.SendMessage(ctx, []kik.Message{kik.TextMessage{SendMessage: kik.SendMessage{To: username, Type: "text"}, Body: "Hi"}}); err != nil {
+		t.Errorf("SendMessage() = %v; want other endpoints unaffected", err)
+	}
+
+	// The probe fails, reopening the circuit, then the next probe succeeds.
+	time.Sleep(30 * time.Millisecond)
+	if state := breaker.State(kik.EndpointUser); state != kik.BreakerHalfOpen {
+		t.Errorf("State() after the cooldown = %v; want half-open", state)
+	}
+	server.Client.GetUser(ctx, username)
+	if state := breaker.State(kik.EndpointUser); state != kik.BreakerOpen {
+		t.Errorf("State() after a failed probe = %v; want open", state)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if _, err := server.Client.GetUser(ctx, username); err != nil {
+		t.Fatalf("GetUser() probe returned an error = %+v; expected no error", err)
+	}
+
+	want := []string{"user closed->open", "user open->half-open", "user half-open->open", "user open->half-open", "user half-open->closed"}
+	if fmt.Sprint(changes) != fmt.Sprint(want) {
+		t.Errorf("OnStateChange got %v; want %v", changes, want)
+	}
+}
+
+func TestCircuitBreaker_IgnoresClientErrors(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+	server.Client.Breaker = kik.NewCircuitBreaker(1, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		if _, err := server.Client.GetUser(context.Background(), "unknown"); errors.Is(err, kik.CircuitOpenError) {
+			t.Fatalf("GetUser() attempt %d = %v; want 404s not to open the circuit", i, err)
+		}
+	}
+}
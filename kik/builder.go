@@ -0,0 +1,293 @@
+package kik
+
+/*
+Message builders
+
+Builders set the type of a message and check it with ValidateMessage when it is built:
+
+	message, err := kik.NewText("Hi").To("laura").Chat(chatId).WithKeyboard(keyboard).Delay(500).Build()
+*/
+
+// TextBuilder builds a TextMessage, see NewText.
+type TextBuilder struct {
+	message TextMessage
+}
+
+// NewText starts building a text message with body.
+func NewText(body string) *TextBuilder {
+	return &TextBuilder{message: TextMessage{SendMessage: SendMessage{Type: "text"}, Body: body}}
+}
+
+// To sets the recipient.
+func (b *TextBuilder) To(username string) *TextBuilder {
+	b.message.To = username
+	return b
+}
+
+// Chat sets the conversation the message is sent in.
+func (b *TextBuilder) Chat(chatId string) *TextBuilder {
+	b.message.ChatId = chatId
+	return b
+}
+
+// Delay sets how many milliseconds to wait before sending the message.
+func (b *TextBuilder) Delay(ms int) *TextBuilder {
+	b.message.Delay = ms
+	return b
+}
+
+// Id sets the id linking the message to its receipts.
+func (b *TextBuilder) Id(id string) *TextBuilder {
+	b.message.Id = id
+	return b
+}
+
+// WithKeyboard adds keyboards, e.g. built with NewKeyboard.
+func (b *TextBuilder) WithKeyboard(keyboards ...SuggestedResponseKeyboard) *TextBuilder {
+	b.message.Keyboards = append(b.message.Keyboards, keyboards...)
+	return b
+}
+
+// TypeTime sets how many milliseconds the bot appears to be typing before the message is shown, after the delay.
+func (b *TextBuilder) TypeTime(ms int) *TextBuilder {
+	b.message.TypeTime = ms
+	return b
+}
+
+// Build returns the message, or the error of ValidateMessage, e.g. if its body is longer than MaxTextLength.
+func (b *TextBuilder) Build() (TextMessage, error) {
+	m := b.message
+	m.Keyboards = append([]SuggestedResponseKeyboard(nil), b.message.Keyboards...)
+	return m, ValidateMessage(m)
+}
+
+// PictureBuilder builds a PictureMessage, see NewPicture.
+type PictureBuilder struct {
+	message PictureMessage
+}
+
+// NewPicture starts building a picture message showing the picture at picUrl.
+func NewPicture(picUrl string) *PictureBuilder {
+	return &PictureBuilder{message: PictureMessage{SendMessage: SendMessage{Type: "picture"}, PicUrl: picUrl}}
+}
+
+// To sets the recipient.
+func (b *PictureBuilder) To(username string) *PictureBuilder {
+	b.message.To = username
+	return b
+}
+
+// Chat sets the conversation the message is sent in.
+func (b *PictureBuilder) Chat(chatId string) *PictureBuilder {
+	b.message.ChatId = chatId
+	return b
+}
+
+// Delay sets how many milliseconds to wait before sending the message.
+func (b *PictureBuilder) Delay(ms int) *PictureBuilder {
+	b.message.Delay = ms
+	return b
+}
+
+// TypeTime sets how many milliseconds the bot appears to be typing before the message is shown, after the delay.
+func (b *PictureBuilder) TypeTime(ms int) *PictureBuilder {
+	b.message.TypeTime = ms
+	return b
+}
+
+// Id sets the id linking the message to its receipts.
+func (b *PictureBuilder) Id(id string) *PictureBuilder {
+	b.message.Id = id
+	return b
+}
+
+// WithKeyboard adds keyboards, e.g. built with NewKeyboard.
+func (b *PictureBuilder) WithKeyboard(keyboards ...SuggestedResponseKeyboard) *PictureBuilder {
+	b.message.Keyboards = append(b.message.Keyboards, keyboards...)
+	return b
+}
+
+// Attribution replaces the bot's name and icon shown on the picture.
+func (b *PictureBuilder) Attribution(a Attribution) *PictureBuilder {
+	b.message.Attribution = &a
+	return b
+}
+
+// Build returns the message, or the error of ValidateMessage, e.g. if it has no recipient.
+func (b *PictureBuilder) Build() (PictureMessage, error) {
+	m := b.message
+	m.Keyboards = append([]SuggestedResponseKeyboard(nil), b.message.Keyboards...)
+	return m, ValidateMessage(m)
+}
+
+// LinkBuilder builds a LinkMessage, see NewLink.
+type LinkBuilder struct {
+	message LinkMessage
+}
+
+// NewLink starts building a link message opening url.
+func NewLink(url string) *LinkBuilder {
+	return &LinkBuilder{message: LinkMessage{SendMessage: SendMessage{Type: "link"}, Url: url}}
+}
+
+// To sets the recipient.
+func (b *LinkBuilder) To(username string) *LinkBuilder {
+	b.message.To = username
+	return b
+}
+
+// Chat sets the conversation the message is sent in.
+func (b *LinkBuilder) Chat(chatId string) *LinkBuilder {
+	b.message.ChatId = chatId
+	return b
+}
+
+// Delay sets how many milliseconds to wait before sending the message.
+func (b *LinkBuilder) Delay(ms int) *LinkBuilder {
+	b.message.Delay = ms
+	return b
+}
+
+// TypeTime sets how many milliseconds the bot appears to be typing before the message is shown, after the delay.
+func (b *LinkBuilder) TypeTime(ms int) *LinkBuilder {
+	b.message.TypeTime = ms
+	return b
+}
+
+// Id sets the id linking the message to its receipts.
+func (b *LinkBuilder) Id(id string) *LinkBuilder {
+	b.message.Id = id
+	return b
+}
+
+// WithKeyboard adds keyboards, e.g. built with NewKeyboard.
+func (b *LinkBuilder) WithKeyboard(keyboards ...SuggestedResponseKeyboard) *LinkBuilder {
+	b.message.Keyboards = append(b.message.Keyboards, keyboards...)
+	return b
+}
+
+// Title sets the title shown at the top of the message.
+func (b *LinkBuilder) Title(title string) *LinkBuilder {
+	b.message.Title = title
+	return b
+}
+
+// Text sets the text shown in the middle of the message.
+func (b *LinkBuilder) Text(text string) *LinkBuilder {
+	b.message.Text = text
+	return b
+}
+
+// Picture sets the picture shown in the message.
+func (b *LinkBuilder) Picture(picUrl string) *LinkBuilder {
+	b.message.PicUrl = picUrl
+	return b
+}
+
+// NoForward prevents the message from being forwarded to other users.
+func (b *LinkBuilder) NoForward() *LinkBuilder {
+	b.message.NoForward = true
+	return b
+}
+
+// KikJsData sets the JSON payload passed to the website using Kik.js.
+func (b *LinkBuilder) KikJsData(data string) *LinkBuilder {
+	b.message.KikJsData = data
+	return b
+}
+
+// Attribution replaces the bot's name and icon shown on the link.
+func (b *LinkBuilder) Attribution(a Attribution) *LinkBuilder {
+	b.message.Attribution = &a
+	return b
+}
+
+// Build returns the message, or the error of ValidateMessage, e.g. if it has no recipient.
+func (b *LinkBuilder) Build() (LinkMessage, error) {
+	m := b.message
+	m.Keyboards = append([]SuggestedResponseKeyboard(nil), b.message.Keyboards...)
+	return m, ValidateMessage(m)
+}
+
+// VideoBuilder builds a VideoMessage, see NewVideo.
+type VideoBuilder struct {
+	message VideoMessage
+}
+
+// NewVideo starts building a video message playing the video at videoUrl.
+func NewVideo(videoUrl string) *VideoBuilder {
+	return &VideoBuilder{message: VideoMessage{SendMessage: SendMessage{Type: "video"}, VideoUrl: videoUrl}}
+}
+
+// To sets the recipient.
+func (b *VideoBuilder) To(username string) *VideoBuilder {
+	b.message.To = username
+	return b
+}
+
+// Chat sets the conversation the message is sent in.
+func (b *VideoBuilder) Chat(chatId string) *VideoBuilder {
+	b.message.ChatId = chatId
+	return b
+}
+
+// Delay sets how many milliseconds to wait before sending the message.
+func (b *VideoBuilder) Delay(ms int) *VideoBuilder {
+	b.message.Delay = ms
+	return b
+}
+
+// TypeTime sets how many milliseconds the bot appears to be typing before the message is shown, after the delay.
+func (b *VideoBuilder) TypeTime(ms int) *VideoBuilder {
+	b.message.TypeTime = ms
+	return b
+}
+
+// Id sets the id linking the message to its receipts.
+func (b *VideoBuilder) Id(id string) *VideoBuilder {
+	b.message.Id = id
+	return b
+}
+
+// WithKeyboard adds keyboards, e.g. built with NewKeyboard.
+func (b *VideoBuilder) WithKeyboard(keyboards ...SuggestedResponseKeyboard) *VideoBuilder {
+	b.message.Keyboards = append(b.message.Keyboards, keyboards...)
+	return b
+}
+
+// Loop plays the video in a loop.
+func (b *VideoBuilder) Loop() *VideoBuilder {
+	b.message.Loop = true
+	return b
+}
+
+// Muted plays the video without audio.
+func (b *VideoBuilder) Muted() *VideoBuilder {
+	b.message.Muted = true
+	return b
+}
+
+// Autoplay plays the video inline, Kik only does so for videos up to MaxAutoplayVideoSize.
+func (b *VideoBuilder) Autoplay() *VideoBuilder {
+	b.message.Autoplay = true
+	return b
+}
+
+// NoSave prevents the user from saving the video to their device.
+func (b *VideoBuilder) NoSave() *VideoBuilder {
+	b.message.NoSave = true
+	return b
+}
+
+// Attribution replaces the bot's name and icon shown on the video.
+func (b *VideoBuilder) Attribution(a Attribution) *VideoBuilder {
+	b.message.Attribution = &a
+	return b
+}
+
+// Build returns the message, or the error of ValidateMessage, e.g. an InvalidVideoError if its videoUrl is not valid.
+func (b *VideoBuilder) Build() (VideoMessage, error) {
+	m := b.message
+	m.Keyboards = append([]SuggestedResponseKeyboard(nil), b.message.Keyboards...)
+	return m, ValidateMessage(m)
+}
@@ -0,0 +1,101 @@
+package kik_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/r-kells/go-kik/kik"
+)
+
+func TestNewText_Build(t *testing.T) {
+	keyboard := kik.NewKeyboard().WithTextResponses("Yes", "No").Build()
+	got, err := kik.NewText("Hi").To("laura").Chat("c1").WithKeyboard(keyboard).Delay(500).TypeTime(200).Build()
+	if err != nil {
+		t.Fatalf("Build() returned an error = %+v; expected no error", err)
+	}
+
+	want := kik.TextMessage{
+		SendMessage: kik.SendMessage{To: "laura", Type: "text", ChatId: "c1", Delay: 500, TypeTime: 200,
+			Keyboards: []kik.SuggestedResponseKeyboard{keyboard}},
+		Body: "Hi",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Build() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestBuilders_Build(t *testing.T) {
+	attribution := kik.Attribution{Preset: kik.AttributionCamera}
+	tests := []struct {
+		name  string
+		build func() (kik.Message, error)
+		want  kik.Message
+	}{
+		{
+			"picture",
+			func() (kik.Message, error) {
+				return kik.NewPicture("https://example.com/cat.png").To("laura").Attribution(attribution).Build()
+			},
+			kik.PictureMessage{SendMessage: kik.SendMessage{To: "laura", Type: "picture"},
+				PicUrl: "https://example.com/cat.png", Attribution: &attribution},
+		},
+		{
+			"link",
+			func() (kik.Message, error) {
+				return kik.NewLink("https://example.com").To("laura").Title("Example").Text("An example").
+					Picture("https://example.com/cat.png").NoForward().KikJsData(`{"a":1}`).Build()
+			},
+			kik.LinkMessage{SendMessage: kik.SendMessage{To: "laura", Type: "link"}, Url: "https://example.com",
+				Title: "Example", Text: "An example", PicUrl: "https://example.com/cat.png", NoForward: true, KikJsData: `{"a":1}`},
+		},
+		{
+			"video",
+			func() (kik.Message, error) {
+				return kik.NewVideo("https://example.com/cat.mp4").To("laura").Id("v1").Loop().Muted().Autoplay().NoSave().Build()
+			},
+			kik.VideoMessage{SendMessage: kik.SendMessage{To: "laura", Type: "video", Id: "v1"},
+				VideoUrl: "https://example.com/cat.mp4", Loop: true, Muted: true, Autoplay: true, NoSave: true},
+		},
+	}
+	for _, test := range tests {
+		got, err := test.build()
+		if err != nil {
+			t.Errorf("Build(%s) returned an error = %+v; expected no error", test.name, err)
+			continue
+		}
+		if diff := cmp.Diff(test.want, got); diff != "" {
+			t.Errorf("Build(%s) mismatch (-want +got):\n%s", test.name, diff)
+		}
+	}
+}
+
+func TestBuilders_BuildInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func() (kik.Message, error)
+		want  error
+	}{
+		{"no recipient", func() (kik.Message, error) { return kik.NewText("Hi").Build() }, kik.InvalidMessageError},
+		{"empty body", func() (kik.Message, error) { return kik.NewText("").To("laura").Build() }, kik.InvalidMessageError},
+		{"long body", func() (kik.Message, error) {
+			return kik.NewText(strings.Repeat("é", kik.MaxTextLength+1)).To("laura").Build()
+		}, kik.InvalidMessageError},
+		{"negative delay", func() (kik.Message, error) { return kik.NewText("Hi").To("laura").Delay(-1).Build() }, kik.InvalidMessageError},
+		{"no picture", func() (kik.Message, error) { return kik.NewPicture("").To("laura").Build() }, kik.InvalidMessageError},
+		{"no url", func() (kik.Message, error) { return kik.NewLink("").To("laura").Build() }, kik.InvalidMessageError},
+		{"relative video", func() (kik.Message, error) { return kik.NewVideo("cat.mp4").To("laura").Build() }, kik.InvalidVideoError},
+	}
+	for _, test := range tests {
+		if _, err := test.build(); !errors.Is(err, test.want) {
+			t.Errorf("Build(%s) = %v; want %v", test.name, err, test.want)
+		}
+	}
+}
+
+func TestNewText_BuildAtMaxLength(t *testing.T) {
+	if _, err := kik.NewText(strings.Repeat("é", kik.MaxTextLength)).To("laura").Build(); err != nil {
+		t.Errorf("Build() returned an error = %+v; expected no error at MaxTextLength", err)
+	}
+}
@@ -0,0 +1,58 @@
+package kik
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// Codec serializes request bodies and deserializes responses and webhook payloads,
+// e.g. to use json-iterator or sonic instead of encoding/json.
+// Responses are decoded straight from the response body, without buffering it.
+type Codec interface {
+	Encode(w io.Writer, v interface{}) error
+	// Decode reads a single value from r into v, an empty r must return io.EOF.
+	Decode(r io.Reader, v interface{}) error
+}
+
+// JSONCodec is the default Codec, using encoding/json.
+type JSONCodec struct{}
+
+// Encode writes v as JSON without escaping HTML, the way request bodies are sent to the Kik API.
+func (JSONCodec) Encode(w io.Writer, v interface{}) error {
+	return encodeJSON(w, v)
+}
+
+func (JSONCodec) Decode(r io.Reader, v interface{}) error {
+	return json.NewDecoder(r).Decode(v)
+}
+
+// WithCodec sets the Codec of the Client, replacing JSONCodec.
+// The Codec must respect the json struct tags and the MarshalJSON and UnmarshalJSON methods of this package.
+func WithCodec(codec Codec) Option {
+	return func(k *Client) error {
+		k.Codec = codec
+		return nil
+	}
+}
+
+func (k *Client) codec() Codec {
+	if k.Codec == nil {
+		return JSONCodec{}
+	}
+	return k.Codec
+}
+
+// unmarshal decodes data into v with codec.
+func unmarshal(codec Codec, data []byte, v interface{}) error {
+	return codec.Decode(bytes.NewReader(data), v)
+}
+
+// unmarshalValue is unmarshal for data holding exactly one value, e.g. a json.RawMessage,
+// which the JSONCodec decodes without the buffering of a json.Decoder.
+func unmarshalValue(codec Codec, data []byte, v interface{}) error {
+	if _, ok := codec.(JSONCodec); ok {
+		return json.Unmarshal(data, v)
+	}
+	return unmarshal(codec, data, v)
+}
@@ -0,0 +1,80 @@
+package kik_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/r-kells/go-kik/kik"
+	"github.com/r-kells/go-kik/kiktest"
+)
+
+// countingCodec is encoding/json, counting how often it is used.
+type countingCodec struct {
+	mu               sync.Mutex
+	encodes, decodes int
+}
+
+func (c *countingCodec) Encode(w io.Writer, v interface{}) error {
+	c.mu.Lock()
+	c.encodes++
+	c.mu.Unlock()
+	return json.NewEncoder(w).Encode(v)
+}
+
+func (c *countingCodec) Decode(r io.Reader, v interface{}) error {
+	c.mu.Lock()
+	c.decodes++
+	c.mu.Unlock()
+	return json.NewDecoder(r).Decode(v)
+}
+
+func TestWithCodec_Requests(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+	server.AddUser(username, &kik.User{FirstName: "Kik"})
+
+	codec := &countingCodec{}
+	if err := kik.WithCodec(codec)(server.Client); err != nil {
+		t.Fatalf("WithCodec() returned an error = %+v; expected no error", err)
+	}
+
+	user, err := server.Client.GetUser(context.Background(), username)
+	if err != nil || user.FirstName != "Kik" {
+		t.Fatalf("GetUser() = %v, %v; want the user", user, err)
+	}
+	err = server.Client.SendMessage(context.Background(), []kik.Message{
+		kik.TextMessage{SendMessage: kik.SendMessage{To: username, Type: "text"}, Body: "Hi"},
+	})
+	if err != nil {
+		t.Fatalf("SendMessage() returned an error = %+v; expected no error", err)
+	}
+
+	if codec.encodes != 1 || codec.decodes != 1 {
+		t.Errorf("codec used for %d encodes and %d decodes; want 1 and 1", codec.encodes, codec.decodes)
+	}
+}
+
+func TestWithCodec_Webhook(t *testing.T) {
+	client, _, teardown := kiktest.TestClient(t)
+	defer teardown()
+	codec := &countingCodec{}
+	client.Codec = codec
+
+	var got []kik.Receive
+	handler := kik.NewWebhookHandler(client, func(ctx context.Context, m kik.Receive) error {
+		got = append(got, m)
+		return nil
+	})
+	rec := postWebhook(handler, sign(client.ApiKey, []byte(textPayload)), textPayload)
+
+	if rec.Code != http.StatusOK || len(got) != 2 {
+		t.Fatalf("ServeHTTP() status = %d with %d messages; want %d with 2", rec.Code, len(got), http.StatusOK)
+	}
+	if codec.decodes == 0 {
+		t.Errorf("the webhook payload was not decoded with the codec")
+	}
+}
@@ -0,0 +1,211 @@
+package kik
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Credentials authenticate requests to the Kik API, the ApiKey also verifies webhook signatures.
+type Credentials struct {
+	BotUsername string
+	ApiKey      string
+}
+
+// CredentialsProvider supplies the Credentials of a Client for every request, so the API key can be rotated
+// without creating a new Client, see WithCredentialsProvider. Implementations must be safe for concurrent use.
+//
+// If a provider also has an Invalidate method, it is called when the Kik API rejects the credentials,
+// and the request is retried once with the Credentials returned next.
+type CredentialsProvider interface {
+	Credentials(ctx context.Context) (Credentials, error)
+}
+
+// WithCredentialsProvider makes the Client ask p for its credentials, instead of using its BotUsername and ApiKey.
+func WithCredentialsProvider(p CredentialsProvider) Option {
+	return func(k *Client) error {
+		k.CredentialsProvider = p
+		return nil
+	}
+}
+
+// VerificationKeyProvider is implemented by CredentialsProviders that know further API keys webhooks
+// may be signed with, e.g. the previous key while Kik switches to a new one.
+type VerificationKeyProvider interface {
+	VerificationKeys(ctx context.Context) ([]string, error)
+}
+
+// WithVerificationKeys makes VerifySignature also accept signatures made with keys, besides the current API key.
+// While rotating the API key, pass the old key so webhooks Kik signed with it before the rotation are not rejected,
+// and remove it once the rotation is complete.
+func WithVerificationKeys(keys ...string) Option {
+	return func(k *Client) error {
+		k.VerificationKeys = append(k.VerificationKeys, keys...)
+		return nil
+	}
+}
+
+// DefaultCredentialsTTL is how long RefreshingCredentials are used before they are fetched again, if TTL is not set.
+const DefaultCredentialsTTL = 5 * time.Minute
+
+// DefaultCredentialsRollover is how long RefreshingCredentials accept the previous API key for webhooks, if Rollover is not set.
+const DefaultCredentialsRollover = 10 * time.Minute
+
+// credentialsRetryDelay is how long RefreshingCredentials keep using their Credentials after a fetch failed,
+// before fetching them again.
+const credentialsRetryDelay = 5 * time.Second
+
+// RefreshingCredentials is a CredentialsProvider fetching the Credentials lazily, e.g. from a secret store,
+// when they are first needed and again once they are older than TTL or were invalidated.
+// Concurrent callers share a single fetch. While it is in flight, and for a few seconds after it failed,
+// callers get the Credentials fetched before, if there are any, instead of waiting for a slow secret store.
+//
+// When a fetch returns a new ApiKey the previous one remains a verification key for Rollover,
+// so webhooks signed before the rotation are still accepted, see VerificationKeyProvider.
+type RefreshingCredentials struct {
+	Fetch    func(ctx context.Context) (Credentials, error)
+	TTL      time.Duration // Defaults to DefaultCredentialsTTL.
+	Rollover time.Duration // Defaults to DefaultCredentialsRollover.
+
+	mu        sync.Mutex
+	current   Credentials
+	fetched   bool      // Whether current was fetched, they are used while refreshing them.
+	fetchedAt time.Time // Zero if current expired or was invalidated.
+	failedAt  time.Time // When the last fetch failed.
+	fetch     *credentialsFetch
+	previous  string // The ApiKey before the last rotation.
+	rotatedAt time.Time
+}
+
+// credentialsFetch is a fetch in flight, done is closed once creds and err are set.
+type credentialsFetch struct {
+	done  chan struct{}
+	creds Credentials
+	err   error
+}
+
+// NewRefreshingCredentials returns RefreshingCredentials fetched with fetch, and refreshed after ttl.
+func NewRefreshingCredentials(fetch func(ctx context.Context) (Credentials, error), ttl time.Duration) *RefreshingCredentials {
+	return &RefreshingCredentials{Fetch: fetch, TTL: ttl}
+}
+
+// Credentials returns the current Credentials, fetching them if they expired.
+func (c *RefreshingCredentials) Credentials(ctx context.Context) (Credentials, error) {
+	c.mu.Lock()
+	ttl := c.TTL
+	if ttl <= 0 {
+		ttl = DefaultCredentialsTTL
+	}
+	if !c.fetchedAt.IsZero() && time.Since(c.fetchedAt) < ttl {
+		defer c.mu.Unlock()
+		return c.current, nil
+	}
+	if c.fetched && (c.fetch != nil || time.Since(c.failedAt) < credentialsRetryDelay) {
+		defer c.mu.Unlock()
+		return c.current, nil
+	}
+
+	f := c.fetch
+	if f != nil {
+		// The first fetch is in flight, there is nothing to use until it is done.
+		c.mu.Unlock()
+		select {
+		case <-f.done:
+			return f.creds, f.err
+		case <-ctx.Done():
+			return Credentials{}, ctx.Err()
+		}
+	}
+
+	f = &credentialsFetch{done: make(chan struct{})}
+	c.fetch = f
+	c.mu.Unlock()
+	f.creds, f.err = c.Fetch(ctx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer close(f.done)
+	c.fetch = nil
+	if f.err != nil {
+		f.creds, c.failedAt = Credentials{}, time.Now()
+		if c.fetched {
+			return c.current, nil
+		}
+		return Credentials{}, f.err
+	}
+	if c.current.ApiKey != "" && c.current.ApiKey != f.creds.ApiKey {
+		c.previous, c.rotatedAt = c.current.ApiKey, time.Now()
+	}
+	c.current, c.fetched, c.fetchedAt = f.creds, true, time.Now()
+	return f.creds, nil
+}
+
+// VerificationKeys returns the previous ApiKey, if it was rotated within Rollover.
+func (c *RefreshingCredentials) VerificationKeys(ctx context.Context) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	rollover := c.Rollover
+	if rollover <= 0 {
+		rollover = DefaultCredentialsRollover
+	}
+	if c.previous == "" || time.Since(c.rotatedAt) >= rollover {
+		return nil, nil
+	}
+	return []string{c.previous}, nil
+}
+
+// Invalidate makes the next call to Credentials fetch them again.
+func (c *RefreshingCredentials) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fetchedAt = time.Time{}
+}
+
+// credentials returns the current bot username and API key, from the CredentialsProvider if set.
+func (k *Client) credentials(ctx context.Context) (string, string, error) {
+	if k.CredentialsProvider != nil {
+		creds, err := k.CredentialsProvider.Credentials(ctx)
+		return creds.BotUsername, creds.ApiKey, err
+	}
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.BotUsername, k.ApiKey, nil
+}
+
+// verificationKeys appends the current API key to keys, followed by the other keys webhook signatures may be made with.
+func (k *Client) verificationKeys(ctx context.Context, keys []string) ([]string, error) {
+	_, apiKey, err := k.credentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	more := k.VerificationKeys
+	if p, ok := k.CredentialsProvider.(VerificationKeyProvider); ok {
+		provided, err := p.VerificationKeys(ctx)
+		if err != nil {
+			return nil, err
+		}
+		more = append(append([]string(nil), more...), provided...)
+	}
+
+	keys = append(keys, apiKey)
+	for _, key := range more {
+		if key != "" && key != apiKey {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// refreshCredentials invalidates the credentials of the provider after err, if they were rejected,
+// and reports whether the request should be sent again with new ones.
+func (k *Client) refreshCredentials(err error) bool {
+	invalidator, ok := k.CredentialsProvider.(interface{ Invalidate() })
+	var apiErr *APIError
+	if !ok || !errors.As(err, &apiErr) || !apiErr.IsUnauthorized() {
+		return false
+	}
+	invalidator.Invalidate()
+	return true
+}
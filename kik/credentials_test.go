@@ -0,0 +1,231 @@
+package kik_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/r-kells/go-kik/kik"
+	"github.com/r-kells/go-kik/kiktest"
+)
+
+// rotatingKeys is a secret store handing out key-1, key-2, ... one per fetch.
+type rotatingKeys struct {
+	mu      sync.Mutex
+	fetches int
+}
+
+func (r *rotatingKeys) fetch(ctx context.Context) (kik.Credentials, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fetches++
+	return kik.Credentials{BotUsername: "bot", ApiKey: fmt.Sprintf("key-%d", r.fetches)}, nil
+}
+
+func (r *rotatingKeys) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.fetches
+}
+
+func TestCredentialsProvider_RefreshesRejectedKey(t *testing.T) {
+	client, mux, teardown := kiktest.TestClient(t)
+	defer teardown()
+
+	mux.HandleFunc(kik.GetUserUrl, func(w http.ResponseWriter, r *http.Request) {
+		if username, key, _ := r.BasicAuth(); username != "bot" || key != "key-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"firstName": "Ryan"}`)
+	})
+	keys := &rotatingKeys{}
+	if err := kik.WithCredentialsProvider(kik.NewRefreshingCredentials(keys.fetch, time.Hour))(client); err != nil {
+		t.Fatalf("WithCredentialsProvider() returned an error = %+v; expected no error", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetUser(context.Background(), username); err != nil {
+			t.Fatalf("GetUser(%s) returned an error = %+v; expected no error", username, err)
+		}
+	}
+	if n := keys.count(); n != 2 {
+		t.Errorf("fetched the credentials %d times; want once, and once more after they were rejected", n)
+	}
+}
+
+func TestCredentialsProvider_Error(t *testing.T) {
+	client, mux, teardown := kiktest.TestClient(t)
+	defer teardown()
+
+	mux.HandleFunc(kik.GetUserUrl, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("request sent without credentials")
+	})
+	vaultDown := errors.New("vault is sealed")
+	client.CredentialsProvider = kik.NewRefreshingCredentials(func(ctx context.Context) (kik.Credentials, error) {
+		return kik.Credentials{}, vaultDown
+	}, 0)
+
+	if _, err := client.GetUser(context.Background(), username); !errors.Is(err, vaultDown) {
+		t.Errorf("GetUser(%s) = %v; want the error of the provider", username, err)
+	}
+	if client.VerifySignature(sign("", []byte("body")), []byte("body")) {
+		t.Errorf("VerifySignature() = true; want signatures to be invalid without credentials")
+	}
+}
+
+func TestCredentialsProvider_ConcurrentRotation(t *testing.T) {
+	client, mux, teardown := kiktest.TestClient(t)
+	defer teardown()
+
+	mux.HandleFunc(kik.GetUserUrl, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"firstName": "Ryan"}`)
+	})
+	keys := &rotatingKeys{}
+	creds := kik.NewRefreshingCredentials(keys.fetch, time.Hour)
+	client.CredentialsProvider = creds
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			creds.Invalidate()
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := client.GetUser(context.Background(), username); err != nil {
+				t.Errorf("GetUser(%s) returned an error = %+v; expected no error", username, err)
+			}
+			client.VerifySignature("invalid sig", []byte("body"))
+		}()
+	}
+	wg.Wait()
+
+	current, _ := creds.Credentials(context.Background())
+	body := []byte(`{"messages": []}`)
+	if !client.VerifySignature(sign(current.ApiKey, body), body) {
+		t.Errorf("VerifySignature() = false; want signatures of the current key %s to be valid", current.ApiKey)
+	}
+}
+
+func TestWithVerificationKeys(t *testing.T) {
+	client, err := kik.NewClient("bot", "new", kik.WithVerificationKeys("old"))
+	if err != nil {
+		t.Fatalf("NewClient returned an error = %+v; expected no error", err)
+	}
+	body := []byte(`{"messages": []}`)
+
+	for key, want := range map[string]bool{"new": true, "old": true, "other": false} {
+		if got := client.VerifySignature(sign(key, body), body); got != want {
+			t.Errorf("VerifySignature(signed with %s) = %v; want %v", key, got, want)
+		}
+	}
+	valid := client.BatchVerify([]kik.SignedPayload{{Signature: sign("old", body), Body: body}, {Signature: sign("other", body), Body: body}})
+	if !valid[0] || valid[1] {
+		t.Errorf("BatchVerify() = %v; want [true false]", valid)
+	}
+}
+
+func TestRefreshingCredentials_Rollover(t *testing.T) {
+	keys := &rotatingKeys{}
+	creds := kik.NewRefreshingCredentials(keys.fetch, time.Hour)
+	client, _ := kik.NewClient("", "", kik.WithCredentialsProvider(creds))
+	body := []byte(`{"messages": []}`)
+
+	creds.Credentials(context.Background())
+	creds.Invalidate()
+	creds.Credentials(context.Background())
+
+	if !client.VerifySignature(sign("key-2", body), body) || !client.VerifySignature(sign("key-1", body), body) {
+		t.Errorf("VerifySignature() = false; want both the new and the previous key to be valid")
+	}
+	creds.Rollover = time.Nanosecond
+	time.Sleep(time.Millisecond)
+	if client.VerifySignature(sign("key-1", body), body) {
+		t.Errorf("VerifySignature(key-1) = true; want the previous key rejected after the rollover")
+	}
+}
+
+func TestRefreshingCredentials_SlowRefresh(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		fetches int
+	)
+	started, release := make(chan struct{}), make(chan struct{})
+	creds := kik.NewRefreshingCredentials(func(ctx context.Context) (kik.Credentials, error) {
+		mu.Lock()
+		fetches++
+		n := fetches
+		mu.Unlock()
+		if n == 1 {
+			return kik.Credentials{BotUsername: "bot", ApiKey: "key-1"}, nil
+		}
+		// The secret store hangs, then fails.
+		close(started)
+		<-release
+		return kik.Credentials{}, errors.New("vault is sealed")
+	}, time.Hour)
+
+	creds.Credentials(context.Background())
+	creds.Invalidate()
+	refreshed := make(chan error)
+	go func() {
+		_, err := creds.Credentials(context.Background())
+		refreshed <- err
+	}()
+	<-started
+
+	if got, err := creds.Credentials(context.Background()); err != nil || got.ApiKey != "key-1" {
+		t.Errorf("Credentials() during a refresh = %+v, %v; want key-1 without waiting", got, err)
+	}
+	close(release)
+	if err := <-refreshed; err != nil {
+		t.Errorf("Credentials() of a failed refresh = %v; want the previous credentials", err)
+	}
+	if got, err := creds.Credentials(context.Background()); err != nil || got.ApiKey != "key-1" {
+		t.Errorf("Credentials() after a failed refresh = %+v, %v; want key-1", got, err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if fetches != 2 {
+		t.Errorf("fetched the credentials %d times; want no fetch right after the refresh failed", fetches)
+	}
+}
+
+func TestVerifyRequest_CredentialsUseRequestContext(t *testing.T) {
+	// A secret store that hangs until the webhook request is cancelled.
+	slow := kik.NewRefreshingCredentials(func(ctx context.Context) (kik.Credentials, error) {
+		<-ctx.Done()
+		return kik.Credentials{}, ctx.Err()
+	}, time.Minute)
+	client, err := kik.NewClient("", "", kik.WithCredentialsProvider(slow))
+	if err != nil {
+		t.Fatalf("NewClient returned an error = %+v; expected no error", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	body := `{"messages": []}`
+	req, _ := http.NewRequestWithContext(ctx, "POST", "/incoming", strings.NewReader(body))
+	req.Header.Set(kik.SignatureHeader, sign("key", []byte(body)))
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.VerifyRequest(req)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != kik.InvalidSignatureError {
+			t.Errorf("VerifyRequest() = %v; want %v", err, kik.InvalidSignatureError)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("VerifyRequest() did not return when the request was cancelled")
+	}
+}
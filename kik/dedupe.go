@@ -0,0 +1,191 @@
+package kik
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DedupeStore remembers keys for a limited time, it is used to drop events Kik delivers more than once.
+type DedupeStore interface {
+	// Seen reports whether key was recorded within the last ttl.
+	// If it was not, key is recorded so that later calls report true until ttl has elapsed.
+	Seen(key string, ttl time.Duration) bool
+	// Forget removes key from the store.
+	Forget(key string)
+}
+
+// MemoryDedupeStore is an in-memory DedupeStore that is safe for concurrent use.
+type MemoryDedupeStore struct {
+	mu        sync.Mutex
+	keys      map[string]time.Time // key -> expiry.
+	lastSweep time.Time
+}
+
+// dedupeSweepInterval is how often expired keys are dropped from a MemoryDedupeStore.
+const dedupeSweepInterval = time.Minute
+
+// NewMemoryDedupeStore returns an empty MemoryDedupeStore.
+func NewMemoryDedupeStore() *MemoryDedupeStore {
+	return &MemoryDedupeStore{keys: make(map[string]time.Time)}
+}
+
+func (s *MemoryDedupeStore) Seen(key string, ttl time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if expiry, ok := s.keys[key]; ok && now.Before(expiry) {
+		return true
+	}
+
+	// Drop expired keys now and then so the map doesn't grow forever.
+	if now.Sub(s.lastSweep) >= dedupeSweepInterval {
+		for k, expiry := range s.keys {
+			if !now.Before(expiry) {
+				delete(s.keys, k)
+			}
+		}
+		s.lastSweep = now
+	}
+	s.keys[key] = now.Add(ttl)
+	return false
+}
+
+func (s *MemoryDedupeStore) Forget(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.keys, key)
+}
+
+// DefaultWelcomeWindow is the window used by a WelcomeThrottler without a Window set.
+const DefaultWelcomeWindow = 24 * time.Hour
+
+// WelcomeThrottler decides whether a user should be welcomed after a start-chatting event.
+// Kik may redeliver start-chatting messages, and users may start chatting repeatedly,
+// a WelcomeThrottler makes sure a welcome is sent at most once per user within Window.
+type WelcomeThrottler struct {
+	Window time.Duration // Defaults to DefaultWelcomeWindow.
+	Store  DedupeStore   // Defaults to a MemoryDedupeStore.
+
+	once sync.Once
+}
+
+// NewWelcomeThrottler returns a WelcomeThrottler backed by a MemoryDedupeStore.
+func NewWelcomeThrottler(window time.Duration) *WelcomeThrottler {
+	return &WelcomeThrottler{Window: window, Store: NewMemoryDedupeStore()}
+}
+
+// ShouldWelcome reports whether username has not been welcomed within the window.
+// It records the welcome, so only the first of several concurrent calls returns true.
+func (w *WelcomeThrottler) ShouldWelcome(username string) bool {
+	w.once.Do(func() {
+		if w.Window <= 0 {
+			w.Window = DefaultWelcomeWindow
+		}
+		if w.Store == nil {
+			w.Store = NewMemoryDedupeStore()
+		}
+	})
+	return !w.Store.Seen("welcome:"+username, w.Window)
+}
+
+// NewMessageId returns a random UUID to use as the Id of an outgoing message.
+func NewMessageId() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	b[6] = b[6]&0x0f | 0x40 // Version 4.
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant.
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// AssignMessageIds sets a NewMessageId on every message without an Id, in place, and returns the Id of each message.
+// The ids link the messages to their delivery and read receipts.
+// Assign them once and pass the same messages when retrying SendMessage, so a Client with a Dedupe store
+// does not send messages again that were sent already.
+func AssignMessageIds(messages []Message) []string {
+	ids := make([]string, len(messages))
+	for i, m := range messages {
+		if m.header().Id == "" {
+			messages[i] = withHeader(m, func(s *SendMessage) { s.Id = NewMessageId() })
+		}
+		ids[i] = messages[i].header().Id
+	}
+	return ids
+}
+
+// DefaultDedupeWindow is how long a Client remembers sent message ids, if its DedupeWindow is not set.
+const DefaultDedupeWindow = time.Hour
+
+// WithDeduplication makes SendMessage skip messages whose Id was sent within window, see Client.Dedupe.
+func WithDeduplication(store DedupeStore, window time.Duration) Option {
+	return func(k *Client) error {
+		k.Dedupe = store
+		k.DedupeWindow = window
+		return nil
+	}
+}
+
+// skipSent records the ids of messages about to be sent, and drops those recorded before.
+func (k *Client) skipSent(messages []Message) []Message {
+	if k.Dedupe == nil {
+		return messages
+	}
+	window := k.DedupeWindow
+	if window <= 0 {
+		window = DefaultDedupeWindow
+	}
+
+	unsent := messages[:0:0]
+	for _, m := range messages {
+		id := m.header().Id
+		if id != "" && k.Dedupe.Seen("message:"+id, window) {
+			k.debug("kik skipping message sent before", "id", id)
+			continue
+		}
+		unsent = append(unsent, m)
+	}
+	return unsent
+}
+
+// forgetRejected forgets the ids of messages Kik responded to with an error, so they can be sent again.
+// Messages of requests that failed without a response may have been sent, so they stay recorded.
+func (k *Client) forgetRejected(chunks []chunk, err error) {
+	if k.Dedupe == nil || err == nil {
+		return
+	}
+
+	rejected := func(err error) bool {
+		var apiErr *APIError
+		return errors.As(err, &apiErr)
+	}
+	forget := func(c chunk) {
+		for _, m := range c.messages {
+			if id := m.header().Id; id != "" {
+				k.Dedupe.Forget("message:" + id)
+			}
+		}
+	}
+
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) {
+		if len(chunks) == 1 && rejected(err) {
+			forget(chunks[0])
+		}
+		return
+	}
+	for _, chunkErr := range batchErr.Errors {
+		if !rejected(chunkErr.Err) {
+			continue
+		}
+		for _, c := range chunks {
+			if c.offset == chunkErr.Offset {
+				forget(c)
+			}
+		}
+	}
+}
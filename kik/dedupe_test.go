@@ -0,0 +1,159 @@
+package kik_test
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/r-kells/go-kik/kik"
+	"github.com/r-kells/go-kik/kiktest"
+)
+
+func TestShouldWelcome_RepeatedStartChatting(t *testing.T) {
+	throttler := kik.NewWelcomeThrottler(time.Hour)
+
+	if !throttler.ShouldWelcome(username) {
+		t.Errorf("ShouldWelcome(%s) = false on first start-chatting; want true", username)
+	}
+	for i := 0; i < 5; i++ {
+		if throttler.ShouldWelcome(username) {
+			t.Errorf("ShouldWelcome(%s) = true on repeated start-chatting; want false", username)
+		}
+	}
+	if !throttler.ShouldWelcome("someoneelse") {
+		t.Errorf("ShouldWelcome(someoneelse) = false; want true")
+	}
+}
+
+func TestShouldWelcome_Concurrent(t *testing.T) {
+	throttler := &kik.WelcomeThrottler{Window: time.Hour}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		welcomes int
+	)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if throttler.ShouldWelcome(username) {
+				mu.Lock()
+				welcomes++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if welcomes != 1 {
+		t.Errorf("got %d welcomes for concurrent start-chatting events; want 1", welcomes)
+	}
+}
+
+func TestShouldWelcome_WindowExpires(t *testing.T) {
+	throttler := &kik.WelcomeThrottler{
+		Window: 20 * time.Millisecond,
+		Store:  kik.NewMemoryDedupeStore(),
+	}
+
+	throttler.ShouldWelcome(username)
+	if throttler.ShouldWelcome(username) {
+		t.Errorf("ShouldWelcome(%s) = true within the window; want false", username)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if !throttler.ShouldWelcome(username) {
+		t.Errorf("ShouldWelcome(%s) = false after the window; want true", username)
+	}
+}
+
+func TestMemoryDedupeStore_Forget(t *testing.T) {
+	store := kik.NewMemoryDedupeStore()
+
+	if store.Seen("id", time.Hour) {
+		t.Errorf("Seen(id) = true for a new key; want false")
+	}
+	store.Forget("id")
+	if store.Seen("id", time.Hour) {
+		t.Errorf("Seen(id) = true after Forget; want false")
+	}
+}
+
+func TestAssignMessageIds(t *testing.T) {
+	messages := []kik.Message{
+		kik.TextMessage{SendMessage: kik.SendMessage{To: username, Type: "text"}, Body: "Hi"},
+		kik.TextMessage{SendMessage: kik.SendMessage{To: username, Type: "text", Id: "mine"}, Body: "Hi"},
+		kik.IsTypingMessage{SendMessage: kik.SendMessage{To: username, Type: "is-typing"}},
+	}
+
+	ids := kik.AssignMessageIds(messages)
+
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	if len(ids) != 3 || !uuid.MatchString(ids[0]) || ids[1] != "mine" || !uuid.MatchString(ids[2]) || ids[0] == ids[2] {
+		t.Fatalf("AssignMessageIds() = %v; want new UUIDs and the existing id", ids)
+	}
+	if got := messages[0].(kik.TextMessage).Id; got != ids[0] {
+		t.Errorf("messages[0].Id = %s; want %s", got, ids[0])
+	}
+	if again := kik.AssignMessageIds(messages); again[0] != ids[0] || again[2] != ids[2] {
+		t.Errorf("AssignMessageIds() again = %v; want the same ids %v", again, ids)
+	}
+}
+
+func TestSendMessage_Deduplicates(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+	if err := kik.WithDeduplication(kik.NewMemoryDedupeStore(), time.Hour)(server.Client); err != nil {
+		t.Fatalf("WithDeduplication() returned an error = %+v; expected no error", err)
+	}
+
+	messages := []kik.Message{kik.TextMessage{SendMessage: kik.SendMessage{To: username, Type: "text"}, Body: "Hi"}}
+	kik.AssignMessageIds(messages)
+
+	for i := 0; i < 3; i++ {
+		if err := server.Client.SendMessage(context.Background(), messages); err != nil {
+			t.Fatalf("SendMessage() returned an error = %+v; expected no error", err)
+		}
+	}
+	if sent := server.Messages(); len(sent) != 1 {
+		t.Errorf("SendMessage() sent %d messages; want the message sent once", len(sent))
+	}
+}
+
+func TestSendMessage_DeduplicationResendsRejected(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+	server.Client.Dedupe = kik.NewMemoryDedupeStore()
+	server.Fail(kik.SendMessageUrl, http.StatusServiceUnavailable, 1)
+
+	messages := []kik.Message{kik.TextMessage{SendMessage: kik.SendMessage{To: username, Type: "text"}, Body: "Hi"}}
+	kik.AssignMessageIds(messages)
+
+	if err := server.Client.SendMessage(context.Background(), messages); err == nil {
+		t.Fatalf("SendMessage() returned no error; want the simulated failure")
+	}
+	if err := server.Client.SendMessage(context.Background(), messages); err != nil {
+		t.Fatalf("SendMessage() returned an error = %+v; expected no error", err)
+	}
+	if sent := server.Messages(); len(sent) != 1 {
+		t.Errorf("SendMessage() sent %d messages; want the rejected message sent again", len(sent))
+	}
+}
+
+func BenchmarkMemoryDedupeStore_Seen(b *testing.B) {
+	store := kik.NewMemoryDedupeStore()
+	keys := make([]string, b.N)
+	for i := range keys {
+		keys[i] = kik.NewMessageId()
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for _, key := range keys {
+		store.Seen(key, time.Hour)
+	}
+}
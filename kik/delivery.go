@@ -0,0 +1,196 @@
+package kik
+
+import (
+	"fmt"
+	"sync"
+)
+
+// DeliveryState is the lifecycle of a message sent by the bot.
+// States only move forward: MessageQueued → MessageSent → MessageDelivered → MessageRead.
+type DeliveryState int
+
+const (
+	MessageQueued    DeliveryState = iota // The message is waiting to be sent.
+	MessageSent                           // The Kik API accepted the message.
+	MessageDelivered                      // A delivery receipt was received.
+	MessageRead                           // A read receipt was received.
+)
+
+func (s DeliveryState) String() string {
+	switch s {
+	case MessageQueued:
+		return "queued"
+	case MessageSent:
+		return "sent"
+	case MessageDelivered:
+		return "delivered"
+	case MessageRead:
+		return "read"
+	}
+	return fmt.Sprintf("DeliveryState(%d)", int(s))
+}
+
+// CanTransition reports whether a message in state s may move to state to.
+// Receipts can arrive out of order or more than once, so states may be skipped or repeated, but never go back.
+func (s DeliveryState) CanTransition(to DeliveryState) bool {
+	return to >= s && to <= MessageRead
+}
+
+// Transition returns the state after moving from s to to,
+// or an InvalidTransitionError if that would move the message backwards.
+func (s DeliveryState) Transition(to DeliveryState) (DeliveryState, error) {
+	if !s.CanTransition(to) {
+		return s, fmt.Errorf("%w: %v to %v", InvalidTransitionError, s, to)
+	}
+	return to, nil
+}
+
+// DeliveryStateFromReceipt returns the state implied by a delivery or read receipt,
+// for all other message types it returns a NotReceiptError.
+func DeliveryStateFromReceipt(r Receive) (DeliveryState, error) {
+	switch r.(type) {
+	case *DeliveryReceiptReceive:
+		return MessageDelivered, nil
+	case *ReadReceiptReceive:
+		return MessageRead, nil
+	}
+	return MessageQueued, fmt.Errorf("%w: %T", NotReceiptError, r)
+}
+
+// ReceiptStore persists the DeliveryState of messages tracked by a Tracker.
+type ReceiptStore interface {
+	// Load returns the state of the message with id, the bool is false if it is not tracked.
+	Load(id string) (DeliveryState, bool, error)
+	// Save stores the state of the message with id.
+	Save(id string, state DeliveryState) error
+}
+
+// MemoryReceiptStore is an in-memory ReceiptStore that is safe for concurrent use.
+// It never forgets messages, long running bots should use a store that expires them.
+type MemoryReceiptStore struct {
+	mu     sync.Mutex
+	states map[string]DeliveryState
+}
+
+// NewMemoryReceiptStore returns an empty MemoryReceiptStore.
+func NewMemoryReceiptStore() *MemoryReceiptStore {
+	return &MemoryReceiptStore{states: make(map[string]DeliveryState)}
+}
+
+func (s *MemoryReceiptStore) Load(id string) (DeliveryState, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state, ok := s.states[id]
+	return state, ok, nil
+}
+
+func (s *MemoryReceiptStore) Save(id string, state DeliveryState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states[id] = state
+	return nil
+}
+
+// Tracker correlates the ids of sent messages with the delivery and read receipts Kik sends for them.
+// Receipts for messages that are not tracked are ignored.
+type Tracker struct {
+	Store ReceiptStore // Defaults to a MemoryReceiptStore.
+
+	// OnChange is called, if set, when a tracked message moves to a new state.
+	OnChange func(id string, state DeliveryState)
+
+	mu   sync.Mutex // serializes updates, so concurrent receipts never move a message backwards.
+	once sync.Once
+}
+
+// NewTracker returns a Tracker persisting states in store.
+func NewTracker(store ReceiptStore) *Tracker {
+	return &Tracker{Store: store}
+}
+
+func (t *Tracker) init() {
+	t.once.Do(func() {
+		if t.Store == nil {
+			t.Store = NewMemoryReceiptStore()
+		}
+	})
+}
+
+// Track starts tracking messages with ids as MessageSent, e.g. using the ids from AssignMessageIds
+// once SendMessage succeeded. Messages that are tracked already keep their state.
+func (t *Tracker) Track(ids ...string) error {
+	t.init()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, id := range ids {
+		_, ok, err := t.Store.Load(id)
+		if err != nil {
+			return err
+		}
+		if ok {
+			continue
+		}
+		if err := t.Store.Save(id, MessageSent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HandleReceipt updates the tracked messages a delivery or read receipt refers to.
+// Other message types return a NotReceiptError.
+func (t *Tracker) HandleReceipt(r Receive) error {
+	state, err := DeliveryStateFromReceipt(r)
+	if err != nil {
+		return err
+	}
+
+	var ids []string
+	switch r := r.(type) {
+	case *DeliveryReceiptReceive:
+		ids = r.MessageIds
+	case *ReadReceiptReceive:
+		ids = r.MessageIds
+	}
+
+	t.init()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, id := range ids {
+		current, ok, err := t.Store.Load(id)
+		if err != nil {
+			return err
+		}
+		// A read receipt may arrive before, or instead of, the delivery receipt.
+		if !ok || current == state || !current.CanTransition(state) {
+			continue
+		}
+		if err := t.Store.Save(id, state); err != nil {
+			return err
+		}
+		if t.OnChange != nil {
+			t.OnChange(id, state)
+		}
+	}
+	return nil
+}
+
+// State returns the state of the message with id, the bool is false if it is not tracked.
+func (t *Tracker) State(id string) (DeliveryState, bool, error) {
+	t.init()
+	return t.Store.Load(id)
+}
+
+// WasDelivered reports whether the message with id was delivered, or read.
+func (t *Tracker) WasDelivered(id string) (bool, error) {
+	state, _, err := t.State(id)
+	return state >= MessageDelivered, err
+}
+
+// WasRead reports whether the message with id was read.
+func (t *Tracker) WasRead(id string) (bool, error) {
+	state, _, err := t.State(id)
+	return state == MessageRead, err
+}
@@ -0,0 +1,154 @@
+package kik_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/r-kells/go-kik/kik"
+)
+
+const receiptsPayload = `{"messages": [
+	{
+		"chatId": "0ee6d46753bfa6ac2f089149959363f3f59ae62b10cba89cc426490ce38ea92d",
+		"id": "0115efde-e54b-43d5-873a-5fef7adc69fd",
+		"type": "delivery-receipt",
+		"from": "laura",
+		"participants": ["laura"],
+		"messageIds": ["d8c8ad8a-3d8f-4b9e-b55b-6cbc4ea8ea89"],
+		"timestamp": 1399303478832,
+		"readReceiptRequested": false
+	},
+	{
+		"chatId": "0ee6d46753bfa6ac2f089149959363f3f59ae62b10cba89cc426490ce38ea92d",
+		"id": "6d8d060c-3ae4-46fc-bb18-6e7ba3182c0f",
+		"type": "read-receipt",
+		"from": "laura",
+		"participants": ["laura"],
+		"messageIds": ["d8c8ad8a-3d8f-4b9e-b55b-6cbc4ea8ea89"],
+		"timestamp": 1399303478833,
+		"readReceiptRequested": false
+	}
+]}`
+
+func TestDeliveryState_Lifecycle(t *testing.T) {
+	var receipts kik.ReceivedMessages
+	if err := json.Unmarshal([]byte(receiptsPayload), &receipts); err != nil {
+		t.Fatalf("Unmarshal returned an error = %+v; expected no error", err)
+	}
+
+	state, err := kik.MessageQueued.Transition(kik.MessageSent)
+	if err != nil {
+		t.Fatalf("Transition(sent) returned an error = %+v; expected no error", err)
+	}
+
+	want := []kik.DeliveryState{kik.MessageDelivered, kik.MessageRead}
+	for i, receipt := range receipts {
+		next, err := kik.DeliveryStateFromReceipt(receipt)
+		if err != nil {
+			t.Fatalf("DeliveryStateFromReceipt(%T) returned an error = %+v; expected no error", receipt, err)
+		}
+		state, err = state.Transition(next)
+		if err != nil {
+			t.Fatalf("Transition(%v) returned an error = %+v; expected no error", next, err)
+		}
+		if state != want[i] {
+			t.Errorf("state = %v; want %v", state, want[i])
+		}
+	}
+
+	if receipts[0].(*kik.DeliveryReceiptReceive).MessageIds[0] != "d8c8ad8a-3d8f-4b9e-b55b-6cbc4ea8ea89" {
+		t.Errorf("MessageIds = %v; want the delivered message id", receipts[0].(*kik.DeliveryReceiptReceive).MessageIds)
+	}
+}
+
+func TestDeliveryState_Transitions(t *testing.T) {
+	tests := []struct {
+		from, to kik.DeliveryState
+		valid    bool
+	}{
+		{kik.MessageQueued, kik.MessageSent, true},
+		{kik.MessageSent, kik.MessageRead, true}, // Delivery receipts are optional.
+		{kik.MessageDelivered, kik.MessageDelivered, true},
+		{kik.MessageRead, kik.MessageDelivered, false},
+		{kik.MessageDelivered, kik.MessageSent, false},
+		{kik.MessageSent, kik.MessageQueued, false},
+		{kik.MessageRead, kik.DeliveryState(10), false},
+	}
+	for _, tt := range tests {
+		got, err := tt.from.Transition(tt.to)
+		if tt.valid && (err != nil || got != tt.to) {
+			t.Errorf("%v.Transition(%v) = %v, %v; want %v", tt.from, tt.to, got, err, tt.to)
+		}
+		if !tt.valid && (!errors.Is(err, kik.InvalidTransitionError) || got != tt.from) {
+			t.Errorf("%v.Transition(%v) = %v, %v; want InvalidTransitionError", tt.from, tt.to, got, err)
+		}
+	}
+}
+
+func TestDeliveryStateFromReceipt_NotReceipt(t *testing.T) {
+	_, err := kik.DeliveryStateFromReceipt(&kik.TextMessageReceive{})
+
+	if !errors.Is(err, kik.NotReceiptError) {
+		t.Errorf("Expected NotReceiptError, got %v", err)
+	}
+}
+
+func TestTracker_Receipts(t *testing.T) {
+	tracker := kik.NewTracker(kik.NewMemoryReceiptStore())
+	var changes []string
+	tracker.OnChange = func(id string, state kik.DeliveryState) {
+		changes = append(changes, id+" "+state.String())
+	}
+
+	if err := tracker.Track("1", "2"); err != nil {
+		t.Fatalf("Track() returned an error = %+v; expected no error", err)
+	}
+	receipts := []kik.Receive{
+		&kik.DeliveryReceiptReceive{MessageIds: []string{"1", "2", "untracked"}},
+		&kik.ReadReceiptReceive{MessageIds: []string{"2"}},
+		// Late or repeated receipts don't move messages backwards.
+		&kik.DeliveryReceiptReceive{MessageIds: []string{"2"}},
+		&kik.ReadReceiptReceive{MessageIds: []string{"2"}},
+	}
+	for _, r := range receipts {
+		if err := tracker.HandleReceipt(r); err != nil {
+			t.Fatalf("HandleReceipt(%+v) returned an error = %+v; expected no error", r, err)
+		}
+	}
+
+	tests := []struct {
+		id              string
+		delivered, read bool
+		wantState       kik.DeliveryState
+		wantTracked     bool
+	}{
+		{"1", true, false, kik.MessageDelivered, true},
+		{"2", true, true, kik.MessageRead, true},
+		{"untracked", false, false, kik.MessageQueued, false},
+	}
+	for _, test := range tests {
+		delivered, _ := tracker.WasDelivered(test.id)
+		read, _ := tracker.WasRead(test.id)
+		state, tracked, _ := tracker.State(test.id)
+		if delivered != test.delivered || read != test.read || state != test.wantState || tracked != test.wantTracked {
+			t.Errorf("message %s: delivered = %v, read = %v, state = %v, tracked = %v; want %v, %v, %v, %v",
+				test.id, delivered, read, state, tracked, test.delivered, test.read, test.wantState, test.wantTracked)
+		}
+	}
+
+	want := []string{"1 delivered", "2 delivered", "2 read"}
+	if len(changes) != len(want) || changes[0] != want[0] || changes[1] != want[1] || changes[2] != want[2] {
+		t.Errorf("OnChange got %v; want %v", changes, want)
+	}
+}
+
+func TestTracker_NotReceipt(t *testing.T) {
+	tracker := &kik.Tracker{}
+
+	err := tracker.HandleReceipt(&kik.TextMessageReceive{})
+
+	if !errors.Is(err, kik.NotReceiptError) {
+		t.Errorf("HandleReceipt(text) = %v; want NotReceiptError", err)
+	}
+}
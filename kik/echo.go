@@ -0,0 +1,50 @@
+package kik
+
+import "fmt"
+
+// Echo converts an incoming message into the equivalent outgoing message, sent back to the same user and chat.
+// Text, picture, link and video messages keep their body or media, other types return a NotEchoableError.
+func Echo(incoming Receive) (Message, error) {
+	switch m := incoming.(type) {
+	case *TextMessageReceive:
+		return TextMessage{
+			SendMessage: echoTo(m.ReceiveMessage),
+			Body:        m.Body,
+		}, nil
+
+	case *PictureMessageReceive:
+		return PictureMessage{
+			SendMessage: echoTo(m.ReceiveMessage),
+			PicUrl:      m.PicUrl,
+			Attribution: m.Attribution,
+		}, nil
+
+	case *LinkMessageReceive:
+		return LinkMessage{
+			SendMessage: echoTo(m.ReceiveMessage),
+			Url:         m.Url,
+			PicUrl:      m.PicUrl,
+			Title:       m.Title,
+			Text:        m.Text,
+			NoForward:   m.NoForward,
+			KikJsData:   m.KikJsData,
+			Attribution: m.Attribution,
+		}, nil
+
+	case *VideoMessageReceive:
+		return VideoMessage{
+			SendMessage: echoTo(m.ReceiveMessage),
+			VideoUrl:    m.VideoUrl,
+			Attribution: m.Attribution,
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: %T", NotEchoableError, incoming)
+}
+
+func echoTo(m ReceiveMessage) SendMessage {
+	return SendMessage{
+		To:     m.From,
+		Type:   m.Type,
+		ChatId: m.ChatId,
+	}
+}
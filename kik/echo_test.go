@@ -0,0 +1,69 @@
+package kik_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/r-kells/go-kik/kik"
+)
+
+func TestEcho_HappyPath(t *testing.T) {
+	received := kik.ReceiveMessage{
+		ChatId: "b3be3bc15dbe59931666c06290abd944aaa769bb2ecaaf859bfb65678880afab",
+		Id:     "6d8d060c-3ae4-46fc-bb18-6e7ba3182c0f",
+		From:   "laura",
+	}
+	withType := func(messageType string) kik.ReceiveMessage {
+		r := received
+		r.Type = messageType
+		return r
+	}
+	sendTo := func(messageType string) kik.SendMessage {
+		return kik.SendMessage{
+			To:     received.From,
+			Type:   messageType,
+			ChatId: received.ChatId,
+		}
+	}
+	attribution := &kik.Attribution{Name: "Attribution Test"}
+
+	tests := []struct {
+		incoming kik.Receive
+		want     kik.Message
+	}{
+		{
+			&kik.TextMessageReceive{ReceiveMessage: withType("text"), Body: "Hi"},
+			kik.TextMessage{SendMessage: sendTo("text"), Body: "Hi"},
+		},
+		{
+			&kik.PictureMessageReceive{ReceiveMessage: withType("picture"), PicUrl: "https://i.imgur.com/TsoLODG.png", Attribution: attribution},
+			kik.PictureMessage{SendMessage: sendTo("picture"), PicUrl: "https://i.imgur.com/TsoLODG.png", Attribution: attribution},
+		},
+		{
+			&kik.LinkMessageReceive{ReceiveMessage: withType("link"), Url: "https://duckduckgo.com/", Title: "DuckDuckGo", NoForward: true},
+			kik.LinkMessage{SendMessage: sendTo("link"), Url: "https://duckduckgo.com/", Title: "DuckDuckGo", NoForward: true},
+		},
+		{
+			&kik.VideoMessageReceive{ReceiveMessage: withType("video"), VideoUrl: "https://example.com/video.mp4"},
+			kik.VideoMessage{SendMessage: sendTo("video"), VideoUrl: "https://example.com/video.mp4"},
+		},
+	}
+	for _, tt := range tests {
+		got, err := kik.Echo(tt.incoming)
+		if err != nil {
+			t.Errorf("Echo(%T) returned an error = %+v; expected no error", tt.incoming, err)
+		}
+		if !cmp.Equal(got, tt.want) {
+			t.Errorf("Echo(%T) = %v; want %v", tt.incoming, got, tt.want)
+		}
+	}
+}
+
+func TestEcho_NotEchoable(t *testing.T) {
+	_, err := kik.Echo(&kik.StickerMessageReceive{StickerPackId: "memes"})
+
+	if !errors.Is(err, kik.NotEchoableError) {
+		t.Errorf("Echo(sticker) = %v; want NotEchoableError", err)
+	}
+}
@@ -0,0 +1,48 @@
+package kik
+
+import "net/http"
+
+// Hook is called around every request to the Kik API, e.g. for logging, metrics or tracing.
+// All fields are optional. Retried requests call the hooks once per attempt,
+// and hooks may be called concurrently when messages are sent in several batches at once.
+type Hook struct {
+	// OnRequest is called before req is sent, payload is the value encoded as its body, or nil.
+	OnRequest func(req *http.Request, payload interface{})
+	// OnResponse is called after a successful response, result is the value its body was decoded into, or nil.
+	// The response body has already been consumed.
+	OnResponse func(req *http.Request, resp *http.Response, result interface{})
+	// OnError is called when a request fails. req is nil if the request could not be created.
+	OnError func(req *http.Request, err error)
+}
+
+// WithHooks adds hooks to the Client, they are called in the order they were added.
+func WithHooks(hooks ...Hook) Option {
+	return func(k *Client) error {
+		k.Hooks = append(k.Hooks, hooks...)
+		return nil
+	}
+}
+
+func (k *Client) onRequest(req *http.Request, payload interface{}) {
+	for _, h := range k.Hooks {
+		if h.OnRequest != nil {
+			h.OnRequest(req, payload)
+		}
+	}
+}
+
+func (k *Client) onResponse(req *http.Request, resp *http.Response, result interface{}) {
+	for _, h := range k.Hooks {
+		if h.OnResponse != nil {
+			h.OnResponse(req, resp, result)
+		}
+	}
+}
+
+func (k *Client) onError(req *http.Request, err error) {
+	for _, h := range k.Hooks {
+		if h.OnError != nil {
+			h.OnError(req, err)
+		}
+	}
+}
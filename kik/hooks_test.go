@@ -0,0 +1,74 @@
+package kik_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/r-kells/go-kik/kik"
+	"github.com/r-kells/go-kik/kiktest"
+)
+
+func TestHooks_CalledAroundRequests(t *testing.T) {
+	client, mux, teardown := kiktest.TestClient(t)
+	defer teardown()
+
+	mux.HandleFunc(kik.GetUserUrl+"ryan", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"firstName": "Ryan"}`)
+	})
+	mux.HandleFunc(kik.GetUserUrl+"missing", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	var calls []string
+	client.Hooks = []kik.Hook{
+		{
+			OnRequest: func(req *http.Request, payload interface{}) {
+				calls = append(calls, "request "+req.URL.Path)
+			},
+			OnResponse: func(req *http.Request, resp *http.Response, result interface{}) {
+				calls = append(calls, fmt.Sprintf("response %d %s", resp.StatusCode, result.(*kik.User).FirstName))
+			},
+			OnError: func(req *http.Request, err error) {
+				var apiErr *kik.APIError
+				if errors.As(err, &apiErr) {
+					calls = append(calls, fmt.Sprintf("error %d", apiErr.StatusCode))
+				}
+			},
+		},
+		{OnRequest: func(req *http.Request, payload interface{}) { calls = append(calls, "second hook") }},
+	}
+
+	client.GetUser(context.Background(), "ryan")
+	client.GetUser(context.Background(), "missing")
+
+	want := []string{
+		"request /v1/user/ryan", "second hook", "response 200 Ryan",
+		"request /v1/user/missing", "second hook", "error 404",
+	}
+	if fmt.Sprint(calls) != fmt.Sprint(want) {
+		t.Errorf("hooks called %q; want %q", calls, want)
+	}
+}
+
+func TestHooks_RequestPayload(t *testing.T) {
+	client, mux, teardown := kiktest.TestClient(t)
+	defer teardown()
+
+	mux.HandleFunc(kik.SendMessageUrl, func(w http.ResponseWriter, r *http.Request) {})
+
+	var got kik.Messages
+	client.Hooks = append(client.Hooks, kik.Hook{
+		OnRequest: func(req *http.Request, payload interface{}) { got = payload.(kik.Messages) },
+	})
+
+	message := kik.TextMessage{SendMessage: kik.SendMessage{To: username, Type: "text"}, Body: "Hi"}
+	client.SendMessage(context.Background(), []kik.Message{message})
+
+	if len(got.Messages) != 1 || !cmp.Equal(got.Messages[0], message) {
+		t.Errorf("OnRequest payload = %v; want the sent messages", got)
+	}
+}
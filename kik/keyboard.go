@@ -0,0 +1,67 @@
+package kik
+
+// Keyboard builds a SuggestedResponseKeyboard.
+//
+//	keyboard := kik.NewKeyboard().WithTextResponses("Yes", "No").Hidden(false).Build()
+type Keyboard struct {
+	keyboard SuggestedResponseKeyboard
+}
+
+// NewKeyboard starts building a suggested response keyboard shown to everyone in the conversation.
+func NewKeyboard() *Keyboard {
+	return &Keyboard{keyboard: SuggestedResponseKeyboard{Type: "suggested"}}
+}
+
+// WithResponses adds responses of any type to the keyboard.
+func (b *Keyboard) WithResponses(responses ...SuggestedResponse) *Keyboard {
+	b.keyboard.Responses = append(b.keyboard.Responses, responses...)
+	return b
+}
+
+// WithTextResponses adds a text response for each of bodies.
+func (b *Keyboard) WithTextResponses(bodies ...string) *Keyboard {
+	for _, body := range bodies {
+		b.WithResponses(KeyboardTextResponse{Type: "text", Body: body})
+	}
+	return b
+}
+
+// WithPictureResponses adds a picture response for each of picUrls.
+func (b *Keyboard) WithPictureResponses(picUrls ...string) *Keyboard {
+	for _, picUrl := range picUrls {
+		b.WithResponses(KeyboardPictureResponse{Type: "picture", PicUrl: picUrl})
+	}
+	return b
+}
+
+// WithFriendPicker adds a friend picker response letting the user pick between min and max friends,
+// with the preselected usernames picked already. Kik requires it to come before any text responses.
+// The picked friends are sent to the bot as a FriendPickerReceive.
+func (b *Keyboard) WithFriendPicker(body string, min, max int8, preselected ...string) *Keyboard {
+	return b.WithResponses(KeyboardFriendPickerResponse{
+		Type:        "friend-picker",
+		Body:        body,
+		Min:         min,
+		Max:         max,
+		Preselected: preselected,
+	})
+}
+
+// To only shows the keyboard to username, instead of everyone in the conversation.
+func (b *Keyboard) To(username string) *Keyboard {
+	b.keyboard.To = username
+	return b
+}
+
+// Hidden sets whether the keyboard is hidden until the user opens it.
+func (b *Keyboard) Hidden(hidden bool) *Keyboard {
+	b.keyboard.Hidden = hidden
+	return b
+}
+
+// Build returns the keyboard, ready to be added to the Keyboards of any outgoing message.
+func (b *Keyboard) Build() SuggestedResponseKeyboard {
+	keyboard := b.keyboard
+	keyboard.Responses = append([]SuggestedResponse(nil), b.keyboard.Responses...)
+	return keyboard
+}
@@ -0,0 +1,115 @@
+package kik_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/r-kells/go-kik/kik"
+	"github.com/r-kells/go-kik/kiktest"
+)
+
+func TestKeyboard_Build(t *testing.T) {
+	got := kik.NewKeyboard().
+		WithFriendPicker("Invite friends", 1, 5).
+		WithTextResponses("Yes", "No").
+		WithPictureResponses("https://i.imgur.com/8rqLdgy.png").
+		To("laura").
+		Hidden(true).
+		Build()
+
+	want := kik.SuggestedResponseKeyboard{
+		Type:   "suggested",
+		To:     "laura",
+		Hidden: true,
+		Responses: []kik.SuggestedResponse{
+			kik.KeyboardFriendPickerResponse{Type: "friend-picker", Body: "Invite friends", Min: 1, Max: 5},
+			kik.KeyboardTextResponse{Type: "text", Body: "Yes"},
+			kik.KeyboardTextResponse{Type: "text", Body: "No"},
+			kik.KeyboardPictureResponse{Type: "picture", PicUrl: "https://i.imgur.com/8rqLdgy.png"},
+		},
+	}
+	if !cmp.Equal(got, want) {
+		t.Errorf("Build() = %v; want %v", got, want)
+	}
+}
+
+func TestKeyboard_BuildIsACopy(t *testing.T) {
+	builder := kik.NewKeyboard().WithTextResponses("Yes")
+	first := builder.Build()
+	builder.WithTextResponses("No")
+
+	if len(first.Responses) != 1 {
+		t.Errorf("Build() responses = %v; want later changes to the builder not to affect it", first.Responses)
+	}
+}
+
+func TestKeyboard_FriendPickerRoundTrip(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+
+	keyboard := kik.NewKeyboard().WithFriendPicker("Invite friends", 1, 3, "aleem").Build()
+	m, err := kik.NewText("Who else should play?").To("laura").Chat("c1").WithKeyboard(keyboard).Build()
+	if err != nil {
+		t.Fatalf("Build() returned an error = %+v; expected no error", err)
+	}
+	if err := server.Client.SendMessage(context.Background(), []kik.Message{m}); err != nil {
+		t.Fatalf("SendMessage() returned an error = %+v; expected no error", err)
+	}
+
+	var sent kik.TextMessage
+	if msgs := server.Messages(); len(msgs) != 1 || msgs[0].Decode(&sent) != nil {
+		t.Fatalf("SendMessage() sent %+v; want one text message", msgs)
+	}
+	want := kik.KeyboardFriendPickerResponse{Type: "friend-picker", Body: "Invite friends", Min: 1, Max: 3, Preselected: []string{"aleem"}}
+	if diff := cmp.Diff([]kik.SuggestedResponse{want}, sent.Keyboards[0].Responses); diff != "" {
+		t.Errorf("sent keyboard mismatch (-want +got):\n%s", diff)
+	}
+
+	var picked []string
+	bot := kik.NewBot(server.Client)
+	bot.HandleFriendPicker(func(ctx context.Context, m *kik.FriendPickerReceive) ([]kik.Message, error) {
+		picked = m.Picked
+		return nil, nil
+	})
+	server.Webhook(bot, kik.FriendPickerReceive{
+		ReceiveMessage: kik.ReceiveMessage{Type: "friend-picker", From: "laura", ChatId: "c1"},
+		Picked:         []string{"aleem", "kikteam"},
+	})
+	if len(picked) != 2 || picked[0] != "aleem" || picked[1] != "kikteam" {
+		t.Errorf("HandleFriendPicker got %v; want aleem and kikteam", picked)
+	}
+}
+
+func TestGetConfiguration_StaticKeyboard(t *testing.T) {
+	client, mux, teardown := kiktest.TestClient(t)
+	defer teardown()
+
+	mux.HandleFunc(kik.ConfigtUrl, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{
+			"webhook": "https://example.com/incoming",
+			"features": {},
+			"staticKeyboard": {
+				"type": "suggested",
+				"responses": [
+					{"type": "text", "body": "Hi"},
+					{"type": "picture", "picUrl": "https://i.imgur.com/8rqLdgy.png"},
+					{"type": "friend-picker", "min": 1, "max": 3}
+				]
+			}
+		}`)
+	})
+
+	config, err := client.GetConfiguration(context.Background())
+	if err != nil {
+		t.Fatalf("GetConfiguration() returned an error = %+v; expected no error", err)
+	}
+
+	want := kik.NewKeyboard().WithTextResponses("Hi").WithPictureResponses("https://i.imgur.com/8rqLdgy.png").
+		WithResponses(kik.KeyboardFriendPickerResponse{Type: "friend-picker", Min: 1, Max: 3}).Build()
+	if !cmp.Equal(*config.StaticKeyboard, want) {
+		t.Errorf("StaticKeyboard = %v; want %v", *config.StaticKeyboard, want)
+	}
+}
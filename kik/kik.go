@@ -3,13 +3,25 @@
 package kik
 
 import (
+	"bytes"
+	"context"
 	"crypto/hmac"
 	"crypto/sha1"
 	"encoding/hex"
 	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultBaseUrl   = "https://api.kik.com/"
+	DefaultUserAgent = "go-kik"
 )
 
 const (
@@ -21,133 +33,536 @@ const (
 )
 
 // Client is used to interface with the Kik bot API.
+// It is safe for concurrent use by multiple goroutines, once its fields are no longer modified.
+// BotUsername and ApiKey may be set directly before the Client is in use,
+// afterwards use SetBotUsername and SetApiKey, or a CredentialsProvider, so concurrent requests are not affected:
+// requests in flight keep the credentials they were sent with, and later ones use the new credentials.
 type Client struct {
 	BotUsername string
 	ApiKey      string
 	Client      *http.Client
 	BaseUrl     *url.URL
+	UserAgent   string          // Sent with every request if set, unless Header has a User-Agent.
+	Header      http.Header     // Sent with every request, see WithHeader.
+	RetryPolicy *RetryPolicy    // Failed requests are not retried if nil.
+	RateLimiter RateLimiter     // Paces every request, including retries, if set.
+	Breaker     *CircuitBreaker // Fails requests fast while an endpoint keeps failing, if set.
+	Hooks       []Hook          // Called around every request.
+	Logger      Logger          // Receives debug logs of every request if set.
+	Metrics     Metrics         // Records every request if set.
+	Codec       Codec           // Encodes requests and decodes responses and webhooks, defaults to JSONCodec.
+	Validate    bool            // Checks messages with ValidateMessages before sending them, see WithValidation.
+
+	// TypingSimulation, if set, is the TypeTime SendMessage gives messages without one, see WithTypingSimulation.
+	TypingSimulation time.Duration
+
+	// EndpointTimeouts bounds each attempt of a request to an endpoint, e.g. EndpointSend, see WithEndpointTimeout.
+	EndpointTimeouts map[string]time.Duration
+
+	// Dedupe, if set, records the ids of sent messages so SendMessage does not send them twice,
+	// e.g. when it is called again after a network error. Ids are remembered for DedupeWindow,
+	// which defaults to DefaultDedupeWindow. Messages Kik rejected with an error status are forgotten,
+	// but a request that failed without a response may have reached Kik, so its messages are treated as sent.
+	Dedupe       DedupeStore
+	DedupeWindow time.Duration
+
+	// UserCache, if set, caches the profiles returned by GetUser for UserCacheTTL, which defaults to DefaultUserCacheTTL.
+	// Use InvalidateUser to drop a profile that is known to have changed.
+	UserCache    UserCache
+	UserCacheTTL time.Duration
+
+	// BatchConcurrency is how many requests are sent at once when messages are split into batches, defaults to 1.
+	BatchConcurrency int
+
+	// CredentialsProvider, if set, supplies the credentials of every request and signature verification,
+	// instead of BotUsername and ApiKey, see WithCredentialsProvider.
+	CredentialsProvider CredentialsProvider
+
+	// VerificationKeys are accepted for webhook signatures besides the current API key, see WithVerificationKeys.
+	VerificationKeys []string
+
+	mu sync.RWMutex // guards BotUsername and ApiKey.
+
+	signatureHash func() hash.Hash // Defaults to sha1.New, see WithSignatureAlgorithm.
+	hmacs         sync.Pool        // of *hmacSet, reused across webhooks.
 }
 
-// NewKikClient is a simple convenience constructor for a Client, you do not have to use it.
-func NewKikClient(baseUrl string, botUsername string, apiKey string, httpClient *http.Client) (*Client, error) {
-	if httpClient == nil {
-		httpClient = &http.Client{}
+// NewClient creates a Client for the Kik API at DefaultBaseUrl, configured by opts.
+// Requests are sent with an http.Client using DefaultTransport, unless WithHttpClient or WithTransport replace it.
+// Options are applied in order, so options modifying the http.Client (e.g. WithTimeout) must come after WithHttpClient.
+func NewClient(botUsername string, apiKey string, opts ...Option) (*Client, error) {
+	baseUrl, _ := url.Parse(DefaultBaseUrl)
+	k := &Client{
+		BotUsername: botUsername,
+		ApiKey:      apiKey,
+		Client:      &http.Client{Transport: DefaultTransport()},
+		BaseUrl:     baseUrl,
+		UserAgent:   DefaultUserAgent}
+
+	for _, opt := range opts {
+		if err := opt(k); err != nil {
+			return nil, err
+		}
 	}
+	return k, nil
+}
 
-	if !strings.HasSuffix(baseUrl, "/") {
-		return nil, fmt.Errorf("BaseURL must have a trailing slash, but %s does not", baseUrl)
+// NewKikClient is a simple convenience constructor for a Client, you do not have to use it.
+// It is equivalent to NewClient with WithBaseUrl and WithHttpClient, followed by opts.
+func NewKikClient(baseUrl string, botUsername string, apiKey string, httpClient *http.Client, opts ...Option) (*Client, error) {
+	opts = append([]Option{WithBaseUrl(baseUrl), WithHttpClient(httpClient)}, opts...)
+	return NewClient(botUsername, apiKey, opts...)
+}
+
+// SetApiKey replaces the API key used for authenticating requests and verifying signatures.
+// Requests already in flight keep the key they were created with.
+func (k *Client) SetApiKey(apiKey string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.ApiKey = apiKey
+}
+
+// SetBotUsername replaces the bot username used for authenticating requests.
+func (k *Client) SetBotUsername(botUsername string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.BotUsername = botUsername
+}
+
+// SetConfiguration replaces the configuration of the bot.
+// The configuration is validated first, an invalid one returns an InvalidConfigurationError without a request.
+func (k *Client) SetConfiguration(ctx context.Context, c *Configuration) error {
+	if err := c.Validate(); err != nil {
+		return err
 	}
-	baseUrlParsed, err := url.Parse(baseUrl)
+	return k.call(ctx, "POST", ConfigtUrl, c, &c)
+}
+
+func (k *Client) GetConfiguration(ctx context.Context) (*Configuration, error) {
+	var config Configuration
+	err := k.call(ctx, "GET", ConfigtUrl, nil, &config)
 	if err != nil {
 		return nil, err
 	}
+	return &config, nil
+}
 
-	return &Client{
-		BotUsername: botUsername,
-		ApiKey:      apiKey,
-		Client:      httpClient,
-		BaseUrl:     baseUrlParsed}, nil
+// SendMessage sends messages to users.
+// Messages exceeding Kik's limits of MaxMessagesPerRequest, or MaxMessagesPerUser to the same user,
+// are split into several requests, if any of them fail a *BatchError is returned.
+//
+// With a Dedupe store, messages whose Id was sent within the DedupeWindow are skipped, see AssignMessageIds.
+// Offsets in a *BatchError then index the messages that were not skipped.
+// With a TypingSimulation, messages are sent as returned by SimulateTyping.
+func (k *Client) SendMessage(ctx context.Context, messages []Message) error {
+	_, err := k.sendMessages(ctx, messages)
+	return err
 }
 
-func (k *Client) SetConfiguration(c *Configuration) error {
-	req, err := k.newRequest("POST", ConfigtUrl, c)
-	if err != nil {
-		return err
+// sendMessages is SendMessage, also returning the chunks the messages were sent in,
+// to match the messages of a failed request after messages sent before were skipped.
+func (k *Client) sendMessages(ctx context.Context, messages []Message) ([]chunk, error) {
+	if k.TypingSimulation > 0 {
+		messages = SimulateTyping(messages, k.TypingSimulation)
+	}
+	if k.Validate {
+		if err := ValidateMessages(messages); err != nil {
+			return nil, err
+		}
 	}
+	chunks := chunkMessages(k.skipSent(messages), MaxMessagesPerRequest, MaxMessagesPerUser)
+	err := k.sendChunks(ctx, SendMessageUrl, chunks)
+	k.forgetRejected(chunks, err)
+	return chunks, err
+}
 
-	req.SetBasicAuth(k.BotUsername, k.ApiKey)
+// SendIsTyping shows, or hides if typing is false, the typing indicator of the bot to a user in chatId.
+func (k *Client) SendIsTyping(ctx context.Context, to string, chatId string, typing bool) error {
+	return k.SendMessage(ctx, []Message{IsTypingMessage{
+		SendMessage: SendMessage{To: to, Type: "is-typing", ChatId: chatId},
+		IsTyping:    typing,
+	}})
+}
 
-	err = k.do(req, &c)
-	if err != nil {
-		return err
-	}
-	return nil
+// SendReadReceipt tells a user the messages with messageIds in chatId were read.
+// Only needed with the ManuallySendReadReceipts feature.
+func (k *Client) SendReadReceipt(ctx context.Context, to string, chatId string, messageIds []string) error {
+	return k.SendMessage(ctx, []Message{ReadReceiptMessage{
+		SendMessage: SendMessage{To: to, Type: "read-receipt", ChatId: chatId},
+		MessageIds:  messageIds,
+	}})
 }
 
-func (k *Client) GetConfiguration() (*Configuration, error) {
-	req, err := k.newRequest("GET", ConfigtUrl, nil)
-	if err != nil {
-		return nil, err
+// EstimateSize returns the size in bytes of the request body SendMessage or BroadcastMessage would send for messages,
+// without sending anything.
+func (k *Client) EstimateSize(messages []Message) (int, error) {
+	var size countingWriter
+	if err := k.codec().Encode(&size, Messages{messages}); err != nil {
+		return 0, err
 	}
+	return int(size), nil
+}
 
-	req.SetBasicAuth(k.BotUsername, k.ApiKey)
+// BroadcastMessage sends messages to many users at once.
+// Kik routes every broadcast message by its own To field, like SendMessage does,
+// so a MissingRecipientError is returned if any message does not set it.
+// More than MaxBroadcastMessages messages are split into several requests, like SendMessage.
+func (k *Client) BroadcastMessage(ctx context.Context, messages []Message) error {
+	for i, m := range messages {
+		if m.header().To == "" {
+			return fmt.Errorf("%w: broadcast message %d", MissingRecipientError, i)
+		}
+	}
+	if k.Validate {
+		if err := ValidateMessages(messages); err != nil {
+			return err
+		}
+	}
+	chunks := chunkMessages(messages, MaxBroadcastMessages, 0)
+	return k.sendChunks(ctx, BroadcastUrl, chunks)
+}
 
-	var config Configuration
-	err = k.do(req, &config)
+// GetUser returns a users profile data as a User struct.
+// With a UserCache a cached profile is returned without calling the API.
+func (k *Client) GetUser(ctx context.Context, username string) (*User, error) {
+	if user, ok := k.cachedUser(username); ok {
+		return user, nil
+	}
+	var user User
+	err := k.call(ctx, "GET", GetUserUrl+username, nil, &user)
 	if err != nil {
 		return nil, err
 	}
-	return &config, nil
+	k.cacheUser(username, &user)
+	return &user, nil
 }
 
-func (k *Client) SendMessage(messages []Message) error {
-	payload := Messages{messages}
-
-	req, err := k.newRequest("POST", SendMessageUrl, payload)
-	if err != nil {
-		return err
+// GetUsers fetches the profiles of usernames with up to concurrency GetUser calls at a time, a concurrency below 1 means 1.
+// It returns the profiles by username, and the errors of the users that could not be fetched, which is nil if none failed.
+func (k *Client) GetUsers(ctx context.Context, usernames []string, concurrency int) (map[string]*User, map[string]error) {
+	if concurrency < 1 {
+		concurrency = 1
 	}
 
-	req.SetBasicAuth(k.BotUsername, k.ApiKey)
+	var (
+		mu    sync.Mutex
+		users = make(map[string]*User, len(usernames))
+		errs  map[string]error
+		seen  = make(map[string]bool, len(usernames))
+		sem   = make(chan struct{}, concurrency)
+		wg    sync.WaitGroup
+	)
+	for _, username := range usernames {
+		if seen[username] {
+			continue
+		}
+		seen[username] = true
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(username string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			user, err := k.GetUser(ctx, username)
 
-	return k.do(req, nil)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if errs == nil {
+					errs = make(map[string]error)
+				}
+				errs[username] = err
+				return
+			}
+			users[username] = user
+		}(username)
+	}
+	wg.Wait()
+	return users, errs
 }
 
-func (k *Client) BroadcastMessage(messages []Message) error {
-	payload := Messages{messages}
+// FetchProfilePic downloads a users profile picture.
+// If ifModifiedSince is not zero the picture is only downloaded if it changed since then,
+// otherwise the returned bool is false and no data is returned.
+func (k *Client) FetchProfilePic(ctx context.Context, user *User, ifModifiedSince time.Time) ([]byte, bool, error) {
+	resp, b, err := k.fetchProfilePic(ctx, user, ifModifiedSince)
+	if err != nil || resp.StatusCode == http.StatusNotModified {
+		return nil, false, err
+	}
+	return b, true, nil
+}
 
-	req, err := k.newRequest("POST", BroadcastUrl, payload)
+// DownloadProfilePicture downloads the profile picture of user.
+// If cached is not nil it is only downloaded again if it changed since cached.LastModified, otherwise cached is returned.
+func (k *Client) DownloadProfilePicture(ctx context.Context, user *User, cached *ProfilePicture) (*ProfilePicture, error) {
+	var ifModifiedSince time.Time
+	if cached != nil {
+		ifModifiedSince = cached.LastModified
+	}
+	resp, b, err := k.fetchProfilePic(ctx, user, ifModifiedSince)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		return cached, nil
+	}
+
+	pic := &ProfilePicture{Data: b, ContentType: resp.Header.Get("Content-Type"), LastModified: user.ProfilePicLastModified.Time}
+	if pic.ContentType == "" {
+		pic.ContentType = http.DetectContentType(b)
+	}
+	if lastModified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		pic.LastModified = lastModified
+	}
+	return pic, nil
+}
 
-	req.SetBasicAuth(k.BotUsername, k.ApiKey)
+// fetchProfilePic downloads the profile picture of user, with a conditional GET if ifModifiedSince is not zero.
+// The response is a 304 Not Modified without data if the picture did not change.
+func (k *Client) fetchProfilePic(ctx context.Context, user *User, ifModifiedSince time.Time) (*http.Response, []byte, error) {
+	if user.ProfilePicUrl == "" {
+		return nil, nil, NoProfilePicError
+	}
 
-	return k.do(req, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", user.ProfilePicUrl, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ifModifiedSince.IsZero() {
+		req.Header.Set("If-Modified-Since", ifModifiedSince.UTC().Format(http.TimeFormat))
+	}
+	return k.download(req)
 }
 
-// GetUser returns a users profile data as a User struct.
-func (k *Client) GetUser(username string) (*User, error) {
-	req, err := k.newRequest("GET", GetUserUrl+username, nil)
+// download sends req, which is not authenticated, and returns the response body.
+// A 304 Not Modified is returned without an error, other non 2xx statuses return an *APIError.
+func (k *Client) download(req *http.Request) (*http.Response, []byte, error) {
+	k.setHeaders(req)
+	resp, err := k.Client.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
+	defer resp.Body.Close()
 
-	req.SetBasicAuth(k.BotUsername, k.ApiKey)
+	if resp.StatusCode == http.StatusNotModified {
+		return resp, nil, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, nil, newAPIError(req, resp)
+	}
 
-	var user User
-	err = k.do(req, &user)
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, b, nil
+}
+
+// CreateCode creates a Kik code embedding s, see NewScanData for deep links.
+// Data larger than MaxScanDataSize returns an InvalidScanDataError.
+func (k *Client) CreateCode(ctx context.Context, s *ScanData) (*Code, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	var code Code
+	err := k.call(ctx, "POST", CodeUrl, s, &code)
 	if err != nil {
 		return nil, err
 	}
-	return &user, nil
+	return &code, nil
 }
 
-func (k *Client) CreateCode(s *ScanData) (*Code, error) {
-	req, err := k.newRequest("POST", CodeUrl, s)
+// DownloadCode downloads the 1024x1024 PNG image of code in color, see Code.Url.
+func (k *Client) DownloadCode(ctx context.Context, code *Code, color CodeColor) ([]byte, error) {
+	u, err := k.BaseUrl.Parse(fmt.Sprintf("%s/%s?c=%d", CodeUrl, url.PathEscape(code.Id), color))
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
 	if err != nil {
 		return nil, err
 	}
 
-	req.SetBasicAuth(k.BotUsername, k.ApiKey)
+	_, b, err := k.download(req)
+	return b, err
+}
 
-	var code Code
-	err = k.do(req, &code)
+// Do sends an authenticated request to path on the Kik API, e.g. "/v1/message", for endpoints this package does not cover.
+// The body, if not nil, is encoded and the response decoded into out, if not nil, with the Codec of the Client.
+// Requests are retried, paced, and fail with an *APIError like those of the other methods, and are reported
+// to Metrics as EndpointOther unless path is one of the known endpoints. Absolute URLs are rejected,
+// so the credentials of the bot are only sent to the BaseUrl.
+func (k *Client) Do(ctx context.Context, method, path string, body, out interface{}) error {
+	u, err := url.Parse(path)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return &code, nil
+	if u.IsAbs() || u.Host != "" {
+		return fmt.Errorf("path %q must be relative to the BaseUrl", path)
+	}
+	return k.call(ctx, method, path, body, out)
 }
 
 // VerifySignature verifies that a request body correctly matches the header signature.
 // For more on signatures see the [docs](https://dev.kik.com/#/docs/messaging#receiving-messages).
+// Surrounding whitespace in signature is ignored, and hex digits may be in any case.
+// Signatures made with the VerificationKeys of the Client are accepted too, see WithVerificationKeys.
+// Signatures are invalid if the CredentialsProvider of the Client fails.
 func (k *Client) VerifySignature(signature string, body []byte) bool {
-	return signature == computeHmac1(body, k.ApiKey)
+	return k.verifySignature(context.Background(), nil, signature, body)
+}
+
+// verifySignature is VerifySignature asking the CredentialsProvider for the keys with ctx, errors are reported for r.
+func (k *Client) verifySignature(ctx context.Context, r *http.Request, signature string, body []byte) bool {
+	var buf [4]string
+	keys, err := k.verificationKeys(ctx, buf[:0])
+	if err != nil {
+		k.onError(r, err)
+		return false
+	}
+	hmacs := k.newHmacs(keys)
+	defer k.hmacs.Put(hmacs)
+	return hmacs.verify(strings.TrimSpace(signature), body)
+}
+
+// VerifyRequest reads the body of a webhook request and verifies it against the signature in its SignatureHeader.
+// The body is returned, and r.Body is replaced so it can be read again.
+// A body larger than MaxWebhookBodySize returns BodyTooLargeError, an invalid signature InvalidSignatureError.
+func (k *Client) VerifyRequest(r *http.Request) ([]byte, error) {
+	return k.verifyRequest(r, new(bytes.Buffer))
+}
+
+// verifyRequest is VerifyRequest reading the body into buf, so the body returned is only valid until buf is reused.
+func (k *Client) verifyRequest(r *http.Request, buf *bytes.Buffer) ([]byte, error) {
+	if r.ContentLength > 0 && r.ContentLength <= MaxWebhookBodySize {
+		// ReadFrom grows by bytes.MinRead before finding the end of the body.
+		buf.Grow(int(r.ContentLength) + bytes.MinRead)
+	}
+	if r.ContentLength > MaxWebhookBodySize {
+		return nil, BodyTooLargeError
+	}
+	// Reading a byte past the limit tells a body that is too large from one that fits exactly.
+	if _, err := buf.ReadFrom(io.LimitReader(r.Body, MaxWebhookBodySize+1)); err != nil {
+		return nil, err
+	}
+	if buf.Len() > MaxWebhookBodySize {
+		return nil, BodyTooLargeError
+	}
+	body := buf.Bytes()
+	r.Body.Close()
+	replay := new(requestBody)
+	replay.Reset(body)
+	r.Body = replay
+
+	if !k.verifySignature(r.Context(), r, r.Header.Get(SignatureHeader), body) {
+		return body, InvalidSignatureError
+	}
+	return body, nil
+}
+
+// requestBody replaces the body of a verified request, so it can be read again.
+type requestBody struct{ bytes.Reader }
+
+func (*requestBody) Close() error { return nil }
+
+// SignedPayload is a request body along with the signature Kik sent it with.
+type SignedPayload struct {
+	Signature string
+	Body      []byte
+}
+
+// BatchVerify verifies many payloads at once, e.g. when reprocessing stored webhooks.
+// The result at each index reports whether the payload at the same index is valid.
+func (k *Client) BatchVerify(payloads []SignedPayload) []bool {
+	valid := make([]bool, len(payloads))
+	keys, err := k.verificationKeys(context.Background(), nil)
+	if err != nil {
+		k.onError(nil, err)
+		return valid
+	}
+	hmacs := k.newHmacs(keys)
+	defer k.hmacs.Put(hmacs)
+
+	for i, p := range payloads {
+		valid[i] = hmacs.verify(strings.TrimSpace(p.Signature), p.Body)
+	}
+	return valid
 }
 
-func computeHmac1(message []byte, secret string) string {
-	key := []byte(secret)
-	h := hmac.New(sha1.New, key)
+// hmacSet is an HMAC for each of keys, with a buffer to verify signatures without allocating.
+type hmacSet struct {
+	keys  []string
+	hmacs []hash.Hash
+	sum   [maxHashSize]byte
+}
+
+// newHmacs returns an HMAC for each of keys, using the configured signature algorithm.
+// HMACs are reused until the keys change, the caller returns the set to k.hmacs when done with it.
+func (k *Client) newHmacs(keys []string) *hmacSet {
+	set, _ := k.hmacs.Get().(*hmacSet)
+	if set == nil {
+		set = new(hmacSet)
+	}
+	if equalKeys(set.keys, keys) {
+		return set
+	}
+
+	h := k.signatureHash
+	if h == nil {
+		h = sha1.New
+	}
+	set.keys = append(set.keys[:0], keys...)
+	set.hmacs = set.hmacs[:0]
+	for _, key := range keys {
+		set.hmacs = append(set.hmacs, hmac.New(h, []byte(key)))
+	}
+	return set
+}
+
+func equalKeys(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// verify reports whether signature is valid for message with any of the HMACs.
+// Every HMAC is computed, so the time taken does not reveal which key matched.
+func (s *hmacSet) verify(signature string, message []byte) bool {
+	valid := false
+	for _, h := range s.hmacs {
+		if verifyHmac(h, s.sum[:0], signature, message) {
+			valid = true
+		}
+	}
+	return valid
+}
+
+// maxHashSize is large enough to hold the sum of any hash Kik may sign with.
+const maxHashSize = 64
+
+// verifyHmac compares the hex encoded signature to the HMAC of message in constant time, appending the HMAC to sum.
+// h is reset before use, so it can be reused across calls.
+func verifyHmac(h hash.Hash, sum []byte, signature string, message []byte) bool {
+	var got [maxHashSize]byte
+
+	// Kik sends upper case hex, but any case is accepted.
+	if hex.DecodedLen(len(signature)) != h.Size() || h.Size() > maxHashSize {
+		return false
+	}
+	// Copied to the stack, as converting the string to a []byte would allocate.
+	var hexSignature [2*maxHashSize + 1]byte
+	n := copy(hexSignature[:], signature)
+	if _, err := hex.Decode(got[:], hexSignature[:n]); err != nil {
+		return false
+	}
+
+	h.Reset()
 	h.Write(message)
-	return hex.EncodeToString(h.Sum(nil))
+	return hmac.Equal(got[:h.Size()], h.Sum(sum))
 }
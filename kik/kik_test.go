@@ -1,11 +1,19 @@
 package kik_test
 
 import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/r-kells/go-kik/kik"
@@ -22,7 +30,7 @@ func TestGetUser_HappyPath(t *testing.T) {
 	expectedUser := &kik.User{
 		FirstName:              "Ryan",
 		LastName:               ".",
-		ProfilePicLastModified: 1560526317131,
+		ProfilePicLastModified: kik.TimestampFromMillis(1560526317131),
 		ProfilePicUrl:          "https://cdn.kik.com/User/pic/rmdkelly/big",
 	}
 
@@ -34,7 +42,7 @@ func TestGetUser_HappyPath(t *testing.T) {
 		fmt.Fprint(w, expectedUser)
 	})
 
-	gotUser, err := client.GetUser("Foo")
+	gotUser, err := client.GetUser(context.Background(), "Foo")
 
 	if err != nil {
 		t.Errorf("GetUser(%s) returned an error = %+v; expected no error", username, err)
@@ -48,7 +56,7 @@ func TestGetUser_404(t *testing.T) {
 	client, _, teardown := kiktest.TestClient(t)
 	defer teardown()
 
-	_, err := client.GetUser(username)
+	_, err := client.GetUser(context.Background(), username)
 
 	// TODO custom error types.
 	if !strings.Contains(fmt.Sprint(err), "404 page not found") {
@@ -67,28 +75,15 @@ func TestGetUser_ShouldFailToDecodeUser(t *testing.T) {
 		fmt.Fprint(w, `{"firstName": true}`)
 	})
 
-	_, err := client.GetUser(username)
+	_, err := client.GetUser(context.Background(), username)
 
 	if !strings.Contains(
 		fmt.Sprint(err),
-		"cannot unmarshal bool into Go struct field User.FirstName of type string") {
+		"cannot unmarshal bool into Go struct field User") {
 		t.Errorf("Expected a json decode error, got %v", err)
 	}
 }
 
-// TODO: can't verify this is working yet, seems like something on Kik side.
-// func TestVerifySignature_Valid(t *testing.T) {
-// 	client, _, teardown := kiktest.TestClient(t)
-// 	defer teardown()
-
-// 	got := client.VerifySignature("AC18D0105C2C257652859322B0499313342C6EB9", []byte("body"))
-// 	want := true
-
-// 	if got != want {
-// 		t.Errorf("Expected signature validation to be correct.")
-// 	}
-// }
-
 func TestVerifySignature_Invalid(t *testing.T) {
 	client, _, teardown := kiktest.TestClient(t)
 	defer teardown()
@@ -99,3 +94,600 @@ func TestVerifySignature_Invalid(t *testing.T) {
 		t.Errorf("Expected signature validation to fail.")
 	}
 }
+
+func TestSetApiKey_ConcurrentRequests(t *testing.T) {
+	client, mux, teardown := kiktest.TestClient(t)
+	defer teardown()
+
+	mux.HandleFunc(kik.GetUserUrl, func(w http.ResponseWriter, r *http.Request) {
+		_, key, ok := r.BasicAuth()
+		if !ok || !strings.HasPrefix(key, "key-") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"firstName": "Ryan"}`)
+	})
+
+	client.SetApiKey("key-0")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			client.SetApiKey(fmt.Sprintf("key-%d", i))
+			client.SetBotUsername(fmt.Sprintf("bot-%d", i))
+		}(i)
+		go func() {
+			defer wg.Done()
+			if _, err := client.GetUser(context.Background(), username); err != nil {
+				t.Errorf("GetUser(%s) returned an error = %+v; expected no error", username, err)
+			}
+			client.VerifySignature("invalid sig", []byte("body"))
+		}()
+	}
+	wg.Wait()
+
+	client.SetApiKey("rotated")
+	if client.ApiKey != "rotated" {
+		t.Errorf("ApiKey = %s; want rotated", client.ApiKey)
+	}
+}
+
+func TestVerifySignature_Valid(t *testing.T) {
+	client, _, teardown := kiktest.TestClient(t)
+	defer teardown()
+
+	body := []byte("body")
+	if !client.VerifySignature(sign(client.ApiKey, body), body) {
+		t.Errorf("Expected signature validation to be correct.")
+	}
+	if !client.VerifySignature(strings.ToLower(sign(client.ApiKey, body)), body) {
+		t.Errorf("Expected lower case signature validation to be correct.")
+	}
+	if !client.VerifySignature(" "+sign(client.ApiKey, body)+"\t", body) {
+		t.Errorf("Expected signature validation with surrounding whitespace to be correct.")
+	}
+}
+
+func TestVerifyRequest(t *testing.T) {
+	client, _, teardown := kiktest.TestClient(t)
+	defer teardown()
+
+	tests := []struct {
+		header    string
+		signature string
+		wantErr   error
+	}{
+		{kik.SignatureHeader, sign(client.ApiKey, []byte("body")), nil},
+		{"x-kik-signature", strings.ToLower(sign(client.ApiKey, []byte("body"))), nil},
+		{kik.SignatureHeader, sign("wrong key", []byte("body")), kik.InvalidSignatureError},
+		{"", "", kik.InvalidSignatureError},
+	}
+	for _, test := range tests {
+		r, _ := http.NewRequest("POST", "/incoming", strings.NewReader("body"))
+		if test.header != "" {
+			r.Header.Set(test.header, test.signature)
+		}
+
+		body, err := client.VerifyRequest(r)
+
+		if err != test.wantErr || string(body) != "body" {
+			t.Errorf("VerifyRequest(%s: %s) = %s, %v; want body, %v", test.header, test.signature, body, err, test.wantErr)
+		}
+		if again, _ := ioutil.ReadAll(r.Body); string(again) != "body" {
+			t.Errorf("VerifyRequest() left the request body %q; want it readable again", again)
+		}
+	}
+}
+
+func TestBatchVerify_MixedPayloads(t *testing.T) {
+	client, _, teardown := kiktest.TestClient(t)
+	defer teardown()
+
+	valid := []byte(`{"messages": []}`)
+	payloads := []kik.SignedPayload{
+		{Signature: sign(client.ApiKey, valid), Body: valid},
+		{Signature: sign(client.ApiKey, valid), Body: []byte(`{"messages": [{}]}`)},
+		{Signature: sign("wrong key", valid), Body: valid},
+		{Signature: "not hex", Body: valid},
+		{Signature: "", Body: valid},
+		{Signature: sign(client.ApiKey, []byte("body")), Body: []byte("body")},
+	}
+
+	got := client.BatchVerify(payloads)
+	want := []bool{true, false, false, false, false, true}
+
+	if !cmp.Equal(got, want) {
+		t.Errorf("BatchVerify() = %v; want %v", got, want)
+	}
+}
+
+// sign returns the upper case hex HMAC-SHA1 signature Kik would send for body.
+func sign(apiKey string, body []byte) string {
+	h := hmac.New(sha1.New, []byte(apiKey))
+	h.Write(body)
+	return strings.ToUpper(hex.EncodeToString(h.Sum(nil)))
+}
+
+func BenchmarkVerifySignature(b *testing.B) {
+	client, err := kik.NewClient("bot", "key")
+	if err != nil {
+		b.Fatal(err)
+	}
+	body := []byte(`{"messages": [{"chatId": "0ee6d467", "id": "1", "type": "text", "from": "laura", "body": "Hi"}]}`)
+	signature := sign(client.ApiKey, body)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(body)))
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if !client.VerifySignature(signature, body) {
+				b.Error("VerifySignature() = false; want true")
+				return
+			}
+		}
+	})
+}
+
+func TestFetchProfilePic_HappyPath(t *testing.T) {
+	client, mux, teardown := kiktest.TestClient(t)
+	defer teardown()
+
+	lastModified := time.Unix(1560526317, 0)
+	mux.HandleFunc("/User/pic/rmdkelly/big", func(w http.ResponseWriter, r *http.Request) {
+		since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+		if err == nil && !lastModified.After(since) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		fmt.Fprint(w, "picture")
+	})
+	user := &kik.User{ProfilePicUrl: client.BaseUrl.String() + "User/pic/rmdkelly/big"}
+
+	tests := []struct {
+		ifModifiedSince time.Time
+		wantBody        []byte
+		wantModified    bool
+	}{
+		{time.Time{}, []byte("picture"), true},
+		{lastModified.Add(-time.Hour), []byte("picture"), true},
+		{lastModified, nil, false},
+		{lastModified.Add(time.Hour), nil, false},
+	}
+	for _, tt := range tests {
+		body, modified, err := client.FetchProfilePic(context.Background(), user, tt.ifModifiedSince)
+		if err != nil {
+			t.Errorf("FetchProfilePic(%v) returned an error = %+v; expected no error", tt.ifModifiedSince, err)
+		}
+		if modified != tt.wantModified || !cmp.Equal(body, tt.wantBody) {
+			t.Errorf("FetchProfilePic(%v) = %q, %v; want %q, %v",
+				tt.ifModifiedSince, body, modified, tt.wantBody, tt.wantModified)
+		}
+	}
+}
+
+func TestFetchProfilePic_NoProfilePic(t *testing.T) {
+	client, _, teardown := kiktest.TestClient(t)
+	defer teardown()
+
+	_, _, err := client.FetchProfilePic(context.Background(), &kik.User{}, time.Time{})
+
+	if err != kik.NoProfilePicError {
+		t.Errorf("Expected NoProfilePicError, got %v", err)
+	}
+}
+
+func TestDownloadProfilePicture(t *testing.T) {
+	client, mux, teardown := kiktest.TestClient(t)
+	defer teardown()
+
+	lastModified := time.Unix(1560526317, 0)
+	var requests int
+	mux.HandleFunc("/User/pic/rmdkelly/big", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+		if err == nil && !lastModified.After(since) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "image/jpeg")
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+		fmt.Fprint(w, "picture")
+	})
+	user := &kik.User{ProfilePicUrl: client.BaseUrl.String() + "User/pic/rmdkelly/big"}
+
+	pic, err := client.DownloadProfilePicture(context.Background(), user, nil)
+	if err != nil {
+		t.Fatalf("DownloadProfilePicture() returned an error = %+v; expected no error", err)
+	}
+	if string(pic.Data) != "picture" || pic.ContentType != "image/jpeg" || !pic.LastModified.Equal(lastModified) {
+		t.Errorf("DownloadProfilePicture() = %+v; want the picture, its content type and last modified time", pic)
+	}
+
+	again, err := client.DownloadProfilePicture(context.Background(), user, pic)
+	if err != nil || again != pic || requests != 2 {
+		t.Errorf("DownloadProfilePicture(cached) = %+v, %v after %d requests; want the cached picture", again, err, requests)
+	}
+
+	if _, err := client.DownloadProfilePicture(context.Background(), &kik.User{}, nil); err != kik.NoProfilePicError {
+		t.Errorf("Expected NoProfilePicError, got %v", err)
+	}
+}
+
+func TestDo(t *testing.T) {
+	client, mux, teardown := kiktest.TestClient(t)
+	defer teardown()
+	client.RetryPolicy = &kik.RetryPolicy{MaxAttempts: 2, RetryableStatusCodes: []int{http.StatusServiceUnavailable}}
+
+	var attempts int
+	mux.HandleFunc("/v1/future", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if username, _, ok := r.BasicAuth(); !ok || username != "test" {
+			t.Errorf("request is not authenticated")
+		}
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var in struct{ Name string }
+		json.NewDecoder(r.Body).Decode(&in)
+		fmt.Fprintf(w, `{"greeting": "hi %s"}`, in.Name)
+	})
+
+	var out struct{ Greeting string }
+	if err := client.Do(context.Background(), "POST", "/v1/future", map[string]string{"name": "laura"}, &out); err != nil {
+		t.Fatalf("Do() returned an error = %+v; expected no error", err)
+	}
+	if out.Greeting != "hi laura" || attempts != 2 {
+		t.Errorf("Do() = %+v after %d attempts; want the decoded response after a retry", out, attempts)
+	}
+
+	var apiErr *kik.APIError
+	if err := client.Do(context.Background(), "GET", "/v1/missing", nil, nil); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Do(/v1/missing) = %v; want an *APIError with status 404", err)
+	}
+	for _, path := range []string{"https://example.com/v1/message", "//example.com/v1/message"} {
+		if err := client.Do(context.Background(), "GET", path, nil, nil); err == nil {
+			t.Errorf("Do(%s) returned no error; want absolute URLs to be rejected", path)
+		}
+	}
+}
+
+func TestBroadcastMessage_HappyPath(t *testing.T) {
+	client, mux, teardown := kiktest.TestClient(t)
+	defer teardown()
+
+	var got map[string][]map[string]interface{}
+	mux.HandleFunc(kik.BroadcastUrl, func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			panic(err)
+		}
+		fmt.Fprint(w, "{}")
+	})
+
+	err := client.BroadcastMessage(context.Background(), []kik.Message{
+		kik.TextMessage{SendMessage: kik.SendMessage{To: "laura", Type: "text"}, Body: "Hi Laura"},
+		kik.TextMessage{SendMessage: kik.SendMessage{To: username, Type: "text"}, Body: "Hi"},
+	})
+
+	if err != nil {
+		t.Errorf("BroadcastMessage() returned an error = %+v; expected no error", err)
+	}
+	if got["messages"][0]["to"] != "laura" || got["messages"][1]["to"] != username {
+		t.Errorf("BroadcastMessage() sent %v; want each message to keep its recipient", got)
+	}
+}
+
+func TestBroadcastMessage_MissingRecipient(t *testing.T) {
+	client, mux, teardown := kiktest.TestClient(t)
+	defer teardown()
+
+	mux.HandleFunc(kik.BroadcastUrl, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("BroadcastMessage() should not send messages without a recipient")
+	})
+
+	err := client.BroadcastMessage(context.Background(), []kik.Message{
+		kik.TextMessage{SendMessage: kik.SendMessage{To: username, Type: "text"}, Body: "Hi"},
+		kik.TextMessage{SendMessage: kik.SendMessage{Type: "text"}, Body: "Hi everyone"},
+	})
+
+	if !errors.Is(err, kik.MissingRecipientError) {
+		t.Errorf("Expected MissingRecipientError, got %v", err)
+	}
+}
+
+func TestCreateCode_HappyPath(t *testing.T) {
+	client, mux, teardown := kiktest.TestClient(t)
+	defer teardown()
+
+	mux.HandleFunc(kik.CodeUrl, func(w http.ResponseWriter, r *http.Request) {
+		var scanData kik.ScanData
+		if err := json.NewDecoder(r.Body).Decode(&scanData); err != nil || scanData.Data != "campaign" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		// Recorded response from the Kik API.
+		fmt.Fprint(w, `{"id": "b4c8c2985ec3f1f8e636d12bd4fb0b1d"}`)
+	})
+
+	code, err := client.CreateCode(context.Background(), &kik.ScanData{Data: "campaign"})
+
+	if err != nil {
+		t.Errorf("CreateCode() returned an error = %+v; expected no error", err)
+	}
+	want := &kik.Code{Id: "b4c8c2985ec3f1f8e636d12bd4fb0b1d"}
+	if !cmp.Equal(code, want) {
+		t.Errorf("CreateCode() = %v; want %v", code, want)
+	}
+}
+
+func TestCodeUrl(t *testing.T) {
+	code := &kik.Code{Id: "b4c8c2985ec3f1f8e636d12bd4fb0b1d"}
+
+	got := code.Url(kik.CodeKikGreen)
+
+	want := "https://api.kik.com/v1/code/b4c8c2985ec3f1f8e636d12bd4fb0b1d?c=4"
+	if got != want {
+		t.Errorf("Url(CodeKikGreen) = %s; want %s", got, want)
+	}
+}
+
+func TestDownloadCode_HappyPath(t *testing.T) {
+	client, mux, teardown := kiktest.TestClient(t)
+	defer teardown()
+
+	png := []byte("\x89PNG")
+	mux.HandleFunc(kik.CodeUrl+"/b4c8c2985ec3f1f8e636d12bd4fb0b1d", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Query().Get("c") != "13" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(png)
+	})
+
+	got, err := client.DownloadCode(context.Background(), &kik.Code{Id: "b4c8c2985ec3f1f8e636d12bd4fb0b1d"}, kik.CodeRoyalPurple)
+
+	if err != nil {
+		t.Errorf("DownloadCode() returned an error = %+v; expected no error", err)
+	}
+	if string(got) != string(png) {
+		t.Errorf("DownloadCode() = %q; want %q", got, png)
+	}
+}
+
+func TestDownloadCode_NotFound(t *testing.T) {
+	client, _, teardown := kiktest.TestClient(t)
+	defer teardown()
+
+	_, err := client.DownloadCode(context.Background(), &kik.Code{Id: "unknown"}, kik.CodeKikBlue)
+
+	var apiErr *kik.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("DownloadCode() = %v; want a 404 *kik.APIError", err)
+	}
+}
+
+func TestEstimateSize_MatchesRequest(t *testing.T) {
+	client, mux, teardown := kiktest.TestClient(t)
+	defer teardown()
+
+	var requestSize int
+	mux.HandleFunc(kik.SendMessageUrl, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requestSize = len(body)
+		fmt.Fprint(w, "{}")
+	})
+
+	messages := []kik.Message{
+		kik.TextMessage{SendMessage: kik.SendMessage{To: username, Type: "text"}, Body: "<b>Not escaped</b> & ünïcödé"},
+		kik.LinkMessage{SendMessage: kik.SendMessage{To: username, Type: "link"}, Url: "https://duckduckgo.com/?q=a&b"},
+	}
+	estimate, err := client.EstimateSize(messages)
+	if err != nil {
+		t.Fatalf("EstimateSize() returned an error = %+v; expected no error", err)
+	}
+	if err := client.SendMessage(context.Background(), messages); err != nil {
+		t.Fatalf("SendMessage() returned an error = %+v; expected no error", err)
+	}
+
+	if estimate != requestSize {
+		t.Errorf("EstimateSize() = %d; want the request size %d", estimate, requestSize)
+	}
+}
+
+func TestSendMessage_Accepted(t *testing.T) {
+	client, mux, teardown := kiktest.TestClient(t)
+	defer teardown()
+
+	mux.HandleFunc(kik.SendMessageUrl, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	err := client.SendMessage(context.Background(), []kik.Message{
+		kik.TextMessage{SendMessage: kik.SendMessage{To: username, Type: "text"}, Body: "Hi"},
+	})
+
+	if err != nil {
+		t.Errorf("SendMessage() returned an error = %+v; expected no error for 202 Accepted", err)
+	}
+}
+
+func TestGetUser_Created(t *testing.T) {
+	client, mux, teardown := kiktest.TestClient(t)
+	defer teardown()
+
+	mux.HandleFunc(kik.GetUserUrl, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"firstName": "Ryan"}`)
+	})
+
+	user, err := client.GetUser(context.Background(), username)
+
+	if err != nil {
+		t.Errorf("GetUser(%s) returned an error = %+v; expected no error for 201 Created", username, err)
+	}
+	if user.FirstName != "Ryan" {
+		t.Errorf("GetUser(%s) = %v; want the body to be parsed", username, user)
+	}
+}
+
+func TestGetUser_ContextCancelled(t *testing.T) {
+	client, mux, teardown := kiktest.TestClient(t)
+	defer teardown()
+
+	unblock := make(chan struct{})
+	defer close(unblock)
+	mux.HandleFunc(kik.GetUserUrl, func(w http.ResponseWriter, r *http.Request) {
+		// Simulate a hung Kik API.
+		select {
+		case <-unblock:
+		case <-r.Context().Done():
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := client.GetUser(ctx, username)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestGetUser_APIError(t *testing.T) {
+	client, mux, teardown := kiktest.TestClient(t)
+	defer teardown()
+
+	mux.HandleFunc(kik.GetUserUrl, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, `{"error": "RateLimitExceeded", "message": "Rate limit exceeded"}`)
+	})
+
+	_, err := client.GetUser(context.Background(), username)
+
+	var apiErr *kik.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected an *kik.APIError, got %v", err)
+	}
+	if apiErr.StatusCode != http.StatusTooManyRequests || apiErr.Code != "RateLimitExceeded" || apiErr.Message != "Rate limit exceeded" {
+		t.Errorf("APIError = %+v; want the status code and Kik error body", apiErr)
+	}
+	if !apiErr.IsRateLimited() || apiErr.IsUnauthorized() {
+		t.Errorf("IsRateLimited() = %v, IsUnauthorized() = %v; want true, false", apiErr.IsRateLimited(), apiErr.IsUnauthorized())
+	}
+	if !errors.Is(err, kik.HttpError) {
+		t.Errorf("Expected the error to match HttpError")
+	}
+}
+
+func TestGetUser_Unauthorized(t *testing.T) {
+	client, mux, teardown := kiktest.TestClient(t)
+	defer teardown()
+
+	mux.HandleFunc(kik.GetUserUrl, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error": "Unauthorized", "message": "Invalid API key"}`)
+	})
+
+	_, err := client.GetUser(context.Background(), username)
+
+	var apiErr *kik.APIError
+	if !errors.As(err, &apiErr) || !apiErr.IsUnauthorized() {
+		t.Errorf("Expected an unauthorized *kik.APIError, got %v", err)
+	}
+}
+
+func TestGetUsers(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+
+	var usernames []string
+	for i := 0; i < 50; i++ {
+		name := fmt.Sprintf("user%d", i)
+		server.AddUser(name, &kik.User{FirstName: name})
+		usernames = append(usernames, name)
+	}
+	usernames = append(usernames, "user0", "unknown")
+
+	users, errs := server.Client.GetUsers(context.Background(), usernames, 8)
+
+	if len(users) != 50 || users["user7"] == nil || users["user7"].FirstName != "user7" {
+		t.Errorf("GetUsers() returned %d users, user7 = %+v; want 50 users", len(users), users["user7"])
+	}
+	var apiErr *kik.APIError
+	if len(errs) != 1 || !errors.As(errs["unknown"], &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("GetUsers() errors = %v; want a not found error for unknown", errs)
+	}
+}
+
+func TestGetUsers_Concurrency(t *testing.T) {
+	client, mux, teardown := kiktest.TestClient(t)
+	defer teardown()
+
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	mux.HandleFunc(kik.GetUserUrl, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		fmt.Fprint(w, `{}`)
+	})
+
+	users, errs := client.GetUsers(context.Background(), []string{"a", "b", "c", "d", "e", "f"}, 2)
+
+	if len(users) != 6 || errs != nil {
+		t.Errorf("GetUsers() = %d users, %v; want 6 users and no errors", len(users), errs)
+	}
+	if maxInFlight > 2 {
+		t.Errorf("GetUsers() sent %d requests at once; want at most 2", maxInFlight)
+	}
+}
+
+func TestSendIsTyping_HappyPath(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+
+	if err := server.Client.SendIsTyping(context.Background(), username, "c1", true); err != nil {
+		t.Fatalf("SendIsTyping() returned an error = %+v; expected no error", err)
+	}
+
+	sent := server.Messages()
+	var got kik.IsTypingMessage
+	if len(sent) != 1 || sent[0].Decode(&got) != nil {
+		t.Fatalf("SendIsTyping() sent %+v; want one message", sent)
+	}
+	want := kik.IsTypingMessage{SendMessage: kik.SendMessage{To: username, Type: "is-typing", ChatId: "c1"}, IsTyping: true}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("SendIsTyping() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSendReadReceipt_HappyPath(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+
+	ids := []string{"1", "2"}
+	if err := server.Client.SendReadReceipt(context.Background(), username, "c1", ids); err != nil {
+		t.Fatalf("SendReadReceipt() returned an error = %+v; expected no error", err)
+	}
+
+	sent := server.Messages()
+	var got kik.ReadReceiptMessage
+	if len(sent) != 1 || sent[0].Decode(&got) != nil {
+		t.Fatalf("SendReadReceipt() sent %+v; want one message", sent)
+	}
+	want := kik.ReadReceiptMessage{SendMessage: kik.SendMessage{To: username, Type: "read-receipt", ChatId: "c1"}, MessageIds: ids}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("SendReadReceipt() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,21 @@
+package kik
+
+// Logger receives debug logs of requests, responses and retries.
+// It is implemented by *slog.Logger, args are alternating keys and values.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+}
+
+// WithLogger sets the Logger of the Client, by default nothing is logged.
+func WithLogger(logger Logger) Option {
+	return func(k *Client) error {
+		k.Logger = logger
+		return nil
+	}
+}
+
+func (k *Client) debug(msg string, args ...interface{}) {
+	if k.Logger != nil {
+		k.Logger.Debug(msg, args...)
+	}
+}
@@ -0,0 +1,73 @@
+package kik_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/r-kells/go-kik/kik"
+	"github.com/r-kells/go-kik/kiktest"
+)
+
+// recordingLogger is a kik.Logger writing each message as a line of key=value pairs, like slog's text handler.
+type recordingLogger struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *recordingLogger) Debug(msg string, args ...interface{}) {
+	line := fmt.Sprintf("msg=%q", msg)
+	for i := 0; i+1 < len(args); i += 2 {
+		line += fmt.Sprintf(" %v=%v", args[i], args[i+1])
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, line)
+}
+
+func (l *recordingLogger) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return strings.Join(l.lines, "\n")
+}
+
+func TestLogger(t *testing.T) {
+	client, mux, teardown := kiktest.TestClient(t)
+	defer teardown()
+
+	logs := &recordingLogger{}
+	client.Logger = logs
+	client.RetryPolicy = kik.DefaultRetryPolicy()
+	client.RetryPolicy.Backoff = time.Millisecond
+
+	attempts := 0
+	mux.HandleFunc(kik.SendMessageUrl, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	})
+
+	client.SendMessage(context.Background(), []kik.Message{
+		kik.TextMessage{SendMessage: kik.SendMessage{To: username, Type: "text"}, Body: "Hi"},
+	})
+
+	got := logs.String()
+	for _, want := range []string{
+		`msg="kik request" method=POST`,
+		`"body":"Hi"}]}`,
+		`msg="kik response" method=POST`,
+		"status=503",
+		`msg="kik retry"`,
+		"attempt=1",
+		"status=200",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("logs are missing %s:\n%s", want, got)
+		}
+	}
+}
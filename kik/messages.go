@@ -0,0 +1,110 @@
+package kik
+
+import (
+	"context"
+	"sync"
+)
+
+// Overflow is what a Bot does with an incoming message when the channel returned by Messages is full.
+type Overflow int
+
+const (
+	// OverflowBlock makes the webhook wait until the message is received. If Kik gives up waiting
+	// and cancels the request, the webhook fails and Kik delivers the message again.
+	OverflowBlock Overflow = iota
+	// OverflowReject fails the webhook with a BufferFullError, so Kik delivers the message again later.
+	// Kik delivers the whole payload again, so the messages of it that were received already are received
+	// a second time, consumers can skip them by their Id, e.g. with a DedupeStore.
+	OverflowReject
+	// OverflowDrop drops the message.
+	OverflowDrop
+)
+
+// IncomingMessage is a message received by a Bot, see Bot.Messages.
+type IncomingMessage struct {
+	Message Receive
+
+	client *Client
+}
+
+// Reply sends messages to the sender of the message, in the same chat, see Client.Reply.
+func (m IncomingMessage) Reply(ctx context.Context, messages ...Message) error {
+	return m.client.Reply(ctx, m.Message, messages...)
+}
+
+// Messages returns a channel receiving the messages no other handler of the Bot matches, replacing HandleDefault,
+// for a select loop instead of handlers:
+//
+//	for m := range bot.Messages(ctx, 100, kik.OverflowReject) {
+//		m.Reply(ctx, kik.TextMessage{SendMessage: kik.SendMessage{Type: "text"}, Body: "Got it"})
+//	}
+//
+// The channel buffers up to buffer messages, overflow decides what happens to messages arriving when it is full.
+// Messages pass the middleware of the Bot, but the webhook returns before they are received, so their
+// Session is not available to the consumer. When ctx is done the channel is closed and the messages are ignored again,
+// unless HandleDefault or Messages were called since.
+func (b *Bot) Messages(ctx context.Context, buffer int, overflow Overflow) <-chan IncomingMessage {
+	s := &subscription{ch: make(chan IncomingMessage, buffer), done: ctx.Done(), overflow: overflow}
+	b.mu.Lock()
+	b.fallback = func(hctx context.Context, m Receive) ([]Message, error) {
+		return nil, s.send(hctx, IncomingMessage{Message: m, client: b.Client})
+	}
+	b.messages = s
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		if b.messages == s {
+			b.fallback, b.messages = nil, nil
+		}
+		b.mu.Unlock()
+		s.close()
+	}()
+	return s.ch
+}
+
+// subscription delivers messages to the channel of Messages, until it is closed.
+type subscription struct {
+	ch       chan IncomingMessage
+	done     <-chan struct{}
+	overflow Overflow
+
+	mu     sync.RWMutex // Held for reading while sending, so the channel is not closed during a send.
+	closed bool
+}
+
+func (s *subscription) send(ctx context.Context, m IncomingMessage) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return nil
+	}
+
+	select {
+	case s.ch <- m:
+		return nil
+	default:
+	}
+	switch s.overflow {
+	case OverflowReject:
+		return BufferFullError
+	case OverflowDrop:
+		return nil
+	}
+	select {
+	case s.ch <- m:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-s.done:
+		return nil
+	}
+}
+
+func (s *subscription) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	close(s.ch)
+}
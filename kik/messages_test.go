@@ -0,0 +1,103 @@
+package kik_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/r-kells/go-kik/kik"
+	"github.com/r-kells/go-kik/kiktest"
+)
+
+func TestBot_Messages(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	bot := kik.NewBot(server.Client)
+	bot.HandleText("/start", func(ctx context.Context, m *kik.TextMessageReceive) ([]kik.Message, error) {
+		return reply("started")
+	})
+	messages := bot.Messages(ctx, 1, kik.OverflowBlock)
+
+	if rec := server.Webhook(bot, textFrom("laura", "c1", "/start"), textFrom("laura", "c1", "Hi")); rec.Code != http.StatusOK {
+		t.Fatalf("ServeHTTP() status = %d; want %d", rec.Code, http.StatusOK)
+	}
+	m := <-messages
+	if text, ok := m.Message.(*kik.TextMessageReceive); !ok || text.Body != "Hi" {
+		t.Fatalf("Messages() received %+v; want Hi", m.Message)
+	}
+
+	if err := m.Reply(ctx, kik.TextMessage{SendMessage: kik.SendMessage{Type: "text"}, Body: "Hello"}); err != nil {
+		t.Fatalf("Reply() returned an error = %+v; expected no error", err)
+	}
+	sent := server.Messages()
+	if len(sent) != 2 || sent[0].Body != "started" || sent[1].Body != "Hello" || sent[1].To != "laura" || sent[1].ChatId != "c1" {
+		t.Errorf("sent %+v; want started and Hello to laura in c1", sent)
+	}
+}
+
+func TestBot_MessagesOverflow(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tests := []struct {
+		overflow kik.Overflow
+		wantErr  error
+	}{
+		{kik.OverflowReject, kik.BufferFullError},
+		{kik.OverflowDrop, nil},
+	}
+	for _, test := range tests {
+		bot := kik.NewBot(server.Client)
+		messages := bot.Messages(ctx, 1, test.overflow)
+		if err := bot.HandleMessage(ctx, textFrom("laura", "c1", "1")); err != nil {
+			t.Fatalf("HandleMessage(1) returned an error = %+v; expected no error", err)
+		}
+		if err := bot.HandleMessage(ctx, textFrom("laura", "c1", "2")); !errors.Is(err, test.wantErr) {
+			t.Errorf("HandleMessage(2) with overflow %d returned %v; want %v", test.overflow, err, test.wantErr)
+		}
+		if m := <-messages; m.Message.(*kik.TextMessageReceive).Body != "1" {
+			t.Errorf("Messages() received %+v; want 1", m.Message)
+		}
+	}
+}
+
+func TestBot_MessagesBlock(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	bot := kik.NewBot(server.Client)
+	bot.Messages(ctx, 0, kik.OverflowBlock)
+
+	reqCtx, reqCancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer reqCancel()
+	if err := bot.HandleMessage(reqCtx, textFrom("laura", "c1", "Hi")); err != context.DeadlineExceeded {
+		t.Errorf("HandleMessage() returned %v; want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestBot_MessagesClosed(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	bot := kik.NewBot(server.Client)
+	messages := bot.Messages(ctx, 1, kik.OverflowReject)
+	cancel()
+
+	if _, ok := <-messages; ok {
+		t.Fatalf("Messages() received a message after cancel; want the channel closed")
+	}
+	if err := bot.HandleMessage(context.Background(), textFrom("laura", "c1", "Hi")); err != nil {
+		t.Errorf("HandleMessage() after cancel returned an error = %+v; expected no error", err)
+	}
+}
@@ -0,0 +1,53 @@
+package kik
+
+import (
+	"strings"
+	"time"
+)
+
+// Endpoint names reported to Metrics.
+const (
+	EndpointSend      = "send"
+	EndpointBroadcast = "broadcast"
+	EndpointUser      = "user"
+	EndpointConfig    = "config"
+	EndpointCode      = "code"
+	EndpointOther     = "other"
+
+	// EndpointWebhook is not an endpoint of the API, it names the incoming messages recorded by ThrottleMetrics.
+	EndpointWebhook = "webhook"
+)
+
+// Metrics records every request to the Kik API, see the kikmetrics package for an implementation.
+// Implementations must be safe for concurrent use.
+type Metrics interface {
+	// ObserveRequest is called once per attempt. statusCode is 0 if no response was received.
+	ObserveRequest(endpoint string, statusCode int, duration time.Duration, err error)
+	// ObserveRetry is called each time a failed attempt is retried.
+	ObserveRetry(endpoint string)
+}
+
+// WithMetrics sets the Metrics of the Client.
+func WithMetrics(m Metrics) Option {
+	return func(k *Client) error {
+		k.Metrics = m
+		return nil
+	}
+}
+
+// endpoint returns the endpoint name of an API path.
+func endpoint(urlStr string) string {
+	switch {
+	case urlStr == SendMessageUrl:
+		return EndpointSend
+	case urlStr == BroadcastUrl:
+		return EndpointBroadcast
+	case strings.HasPrefix(urlStr, GetUserUrl):
+		return EndpointUser
+	case urlStr == ConfigtUrl:
+		return EndpointConfig
+	case strings.HasPrefix(urlStr, CodeUrl):
+		return EndpointCode
+	}
+	return EndpointOther
+}
@@ -0,0 +1,187 @@
+package kik
+
+import (
+	"fmt"
+	"hash"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Option configures a Client, see NewKikClient.
+type Option func(*Client) error
+
+// WithBaseUrl sets the URL of the Kik API, it must have a trailing slash.
+func WithBaseUrl(baseUrl string) Option {
+	return func(k *Client) error {
+		if !strings.HasSuffix(baseUrl, "/") {
+			return fmt.Errorf("BaseURL must have a trailing slash, but %s does not", baseUrl)
+		}
+		baseUrlParsed, err := url.Parse(baseUrl)
+		if err != nil {
+			return err
+		}
+		k.BaseUrl = baseUrlParsed
+		return nil
+	}
+}
+
+// WithHttpClient sets the http.Client used to send requests, a nil httpClient keeps the default.
+func WithHttpClient(httpClient *http.Client) Option {
+	return func(k *Client) error {
+		if httpClient != nil {
+			k.Client = httpClient
+		}
+		return nil
+	}
+}
+
+// WithTransport sets the transport of a copy of the Client's http.Client, replacing DefaultTransport.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(k *Client) error {
+		httpClient := *k.Client
+		httpClient.Transport = transport
+		k.Client = &httpClient
+		return nil
+	}
+}
+
+// DefaultTransport returns the transport of the http.Client created by NewClient.
+// Compared to http.DefaultTransport it keeps more idle connections to the Kik API alive, so busy bots reuse
+// connections instead of exhausting ephemeral ports, and it bounds how long to wait for response headers.
+func DefaultTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = 100
+	transport.MaxIdleConnsPerHost = 64 // Nearly every request goes to the same host.
+	transport.IdleConnTimeout = 90 * time.Second
+	transport.TLSHandshakeTimeout = 10 * time.Second
+	transport.ResponseHeaderTimeout = 30 * time.Second
+	transport.ExpectContinueTimeout = time.Second
+	transport.ForceAttemptHTTP2 = true
+	return transport
+}
+
+// WithTimeout sets the timeout of every request, on a copy of the Client's http.Client.
+func WithTimeout(timeout time.Duration) Option {
+	return func(k *Client) error {
+		httpClient := *k.Client
+		httpClient.Timeout = timeout
+		k.Client = &httpClient
+		return nil
+	}
+}
+
+// WithEndpointTimeout bounds each attempt of a request to endpoint, an endpoint name like EndpointBroadcast
+// or an API path like SendMessageUrl, so slow endpoints can get more time than the rest.
+// The timeout applies on top of the http.Client Timeout, and retries of a RetryPolicy get a timeout of their own.
+func WithEndpointTimeout(endpointOrUrl string, timeout time.Duration) Option {
+	return func(k *Client) error {
+		name := endpointOrUrl
+		if strings.HasPrefix(endpointOrUrl, "/") {
+			name = endpoint(endpointOrUrl)
+		}
+		if k.EndpointTimeouts == nil {
+			k.EndpointTimeouts = make(map[string]time.Duration)
+		}
+		k.EndpointTimeouts[name] = timeout
+		return nil
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request, replacing DefaultUserAgent.
+func WithUserAgent(userAgent string) Option {
+	return func(k *Client) error {
+		k.UserAgent = userAgent
+		return nil
+	}
+}
+
+// WithHeader adds a header sent with every request, including downloads of codes and profile pictures.
+// It may be used repeatedly, also for the same key. A User-Agent header replaces the one of WithUserAgent,
+// Authorization and Content-Type are set by the Client and can not be replaced.
+func WithHeader(key, value string) Option {
+	return func(k *Client) error {
+		switch http.CanonicalHeaderKey(key) {
+		case "Authorization", "Content-Type":
+			return fmt.Errorf("the %s header is set by the Client and can not be replaced", key)
+		}
+		if k.Header == nil {
+			k.Header = make(http.Header)
+		}
+		k.Header.Add(key, value)
+		return nil
+	}
+}
+
+// WithRetryPolicy sets how failed requests are retried, see RetryPolicy.
+func WithRetryPolicy(policy *RetryPolicy) Option {
+	return func(k *Client) error {
+		k.RetryPolicy = policy
+		return nil
+	}
+}
+
+// WithRateLimiter sets the RateLimiter pacing requests.
+func WithRateLimiter(limiter RateLimiter) Option {
+	return func(k *Client) error {
+		k.RateLimiter = limiter
+		return nil
+	}
+}
+
+// WithBatchConcurrency sets how many requests are sent at once when messages are split into batches.
+func WithBatchConcurrency(concurrency int) Option {
+	return func(k *Client) error {
+		k.BatchConcurrency = concurrency
+		return nil
+	}
+}
+
+// WithConnectionTimeouts bounds how long dialing a connection and the TLS handshake may take, so requests fail fast on flaky networks.
+// A zero duration leaves the corresponding setting of the transport unchanged.
+//
+// The timeouts are applied to a copy of the Client's transport (DefaultTransport if none is set),
+// overriding the dialer and TLSHandshakeTimeout of a user-supplied *http.Transport.
+// The http.Client is copied as well, so the one passed to NewKikClient is never modified.
+// The overall http.Client Timeout still applies on top of these.
+// Transports that are not an *http.Transport can not be configured and return an error.
+func WithConnectionTimeouts(dial, tlsHandshake time.Duration) Option {
+	return func(k *Client) error {
+		httpClient := *k.Client
+
+		var transport *http.Transport
+		switch t := httpClient.Transport.(type) {
+		case nil:
+			transport = DefaultTransport()
+		case *http.Transport:
+			transport = t.Clone()
+		default:
+			return fmt.Errorf("can not set connection timeouts on transport of type %T", t)
+		}
+
+		if dial > 0 {
+			transport.DialContext = (&net.Dialer{
+				Timeout:   dial,
+				KeepAlive: 30 * time.Second,
+			}).DialContext
+		}
+		if tlsHandshake > 0 {
+			transport.TLSHandshakeTimeout = tlsHandshake
+		}
+
+		httpClient.Transport = transport
+		k.Client = &httpClient
+		return nil
+	}
+}
+
+// WithSignatureAlgorithm sets the hash used to verify webhook signatures, e.g. sha256.New.
+// Kik currently signs with HMAC-SHA1, which is the default.
+func WithSignatureAlgorithm(h func() hash.Hash) Option {
+	return func(k *Client) error {
+		k.signatureHash = h
+		return nil
+	}
+}
@@ -0,0 +1,306 @@
+package kik_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/r-kells/go-kik/kik"
+)
+
+func TestWithConnectionTimeouts_StalledHandshake(t *testing.T) {
+	// A server that accepts connections but never completes the TLS handshake.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("could not listen: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	client, err := kik.NewKikClient(
+		"https://"+listener.Addr().String()+"/",
+		"test",
+		"test",
+		&http.Client{Timeout: 10 * time.Second},
+		kik.WithConnectionTimeouts(time.Second, 50*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatalf("NewKikClient returned an error = %+v; expected no error", err)
+	}
+
+	start := time.Now()
+	_, err = client.GetUser(context.Background(), username)
+
+	if !strings.Contains(fmt.Sprint(err), "TLS handshake timeout") {
+		t.Errorf("Expected a TLS handshake timeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("GetUser took %v; expected to fail fast", elapsed)
+	}
+}
+
+func TestWithConnectionTimeouts_DoesNotModifyHttpClient(t *testing.T) {
+	transport := &http.Transport{TLSHandshakeTimeout: time.Minute}
+	httpClient := &http.Client{Transport: transport}
+
+	client, err := kik.NewKikClient("https://api.kik.com/", "test", "test", httpClient,
+		kik.WithConnectionTimeouts(0, time.Second))
+	if err != nil {
+		t.Fatalf("NewKikClient returned an error = %+v; expected no error", err)
+	}
+
+	if httpClient.Transport != transport || transport.TLSHandshakeTimeout != time.Minute {
+		t.Errorf("WithConnectionTimeouts modified the supplied http.Client")
+	}
+	got := client.Client.Transport.(*http.Transport).TLSHandshakeTimeout
+	if got != time.Second {
+		t.Errorf("TLSHandshakeTimeout = %v; want %v", got, time.Second)
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestWithConnectionTimeouts_UnsupportedTransport(t *testing.T) {
+	httpClient := &http.Client{Transport: roundTripperFunc(http.DefaultTransport.RoundTrip)}
+
+	_, err := kik.NewKikClient("https://api.kik.com/", "test", "test", httpClient,
+		kik.WithConnectionTimeouts(time.Second, time.Second))
+
+	if err == nil {
+		t.Errorf("Expected an error for a transport that is not an *http.Transport")
+	}
+}
+
+func TestWithSignatureAlgorithm(t *testing.T) {
+	body := []byte(`{"messages": []}`)
+	signWith := func(h func() hash.Hash) string {
+		mac := hmac.New(h, []byte("test"))
+		mac.Write(body)
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+
+	tests := []struct {
+		name    string
+		opts    []kik.Option
+		valid   string
+		invalid string
+	}{
+		{"default", nil, signWith(sha1.New), signWith(sha256.New)},
+		{"sha1", []kik.Option{kik.WithSignatureAlgorithm(sha1.New)}, signWith(sha1.New), signWith(sha256.New)},
+		{"sha256", []kik.Option{kik.WithSignatureAlgorithm(sha256.New)}, signWith(sha256.New), signWith(sha1.New)},
+	}
+	for _, tt := range tests {
+		client, err := kik.NewKikClient("https://api.kik.com/", "test", "test", nil, tt.opts...)
+		if err != nil {
+			t.Fatalf("NewKikClient returned an error = %+v; expected no error", err)
+		}
+
+		if !client.VerifySignature(tt.valid, body) {
+			t.Errorf("%s: Expected signature validation to be correct.", tt.name)
+		}
+		if client.VerifySignature(tt.invalid, body) {
+			t.Errorf("%s: Expected signature of another algorithm to fail.", tt.name)
+		}
+		if got := client.BatchVerify([]kik.SignedPayload{{Signature: tt.valid, Body: body}}); !got[0] {
+			t.Errorf("%s: Expected batch signature validation to be correct.", tt.name)
+		}
+	}
+}
+
+func TestNewClient_Defaults(t *testing.T) {
+	client, err := kik.NewClient("bot", "key")
+	if err != nil {
+		t.Fatalf("NewClient returned an error = %+v; expected no error", err)
+	}
+
+	if client.BaseUrl.String() != kik.DefaultBaseUrl || client.UserAgent != kik.DefaultUserAgent || client.Client == nil {
+		t.Errorf("NewClient() = %+v; want the default base URL, user agent and http.Client", client)
+	}
+	if client.BotUsername != "bot" || client.ApiKey != "key" {
+		t.Errorf("NewClient() credentials = %s, %s; want bot, key", client.BotUsername, client.ApiKey)
+	}
+}
+
+func TestNewClient_DefaultTransport(t *testing.T) {
+	client, _ := kik.NewClient("bot", "key")
+
+	transport, ok := client.Client.Transport.(*http.Transport)
+	if !ok || transport.MaxIdleConnsPerHost != kik.DefaultTransport().MaxIdleConnsPerHost || !transport.ForceAttemptHTTP2 {
+		t.Errorf("NewClient() transport = %+v; want DefaultTransport", client.Client.Transport)
+	}
+
+	rt := roundTripperFunc(http.DefaultTransport.RoundTrip)
+	httpClient := &http.Client{}
+	client, err := kik.NewClient("bot", "key", kik.WithHttpClient(httpClient), kik.WithTransport(rt))
+	if err != nil {
+		t.Fatalf("NewClient returned an error = %+v; expected no error", err)
+	}
+	if _, ok := client.Client.Transport.(roundTripperFunc); !ok || httpClient.Transport != nil {
+		t.Errorf("WithTransport should set the transport on a copy of the http.Client")
+	}
+}
+
+// benchmarkSendMessage sends messages from parallel goroutines through transport to a local server.
+func benchmarkSendMessage(b *testing.B, transport http.RoundTripper) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{}`)
+	}))
+	defer server.Close()
+
+	client, err := kik.NewClient("bot", "key", kik.WithBaseUrl(server.URL+"/"), kik.WithTransport(transport))
+	if err != nil {
+		b.Fatal(err)
+	}
+	messages := []kik.Message{kik.TextMessage{SendMessage: kik.SendMessage{To: username, Type: "text"}, Body: "Hi"}}
+
+	b.SetParallelism(16)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if err := client.SendMessage(context.Background(), messages); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
+
+func BenchmarkSendMessage_DefaultTransport(b *testing.B) {
+	benchmarkSendMessage(b, kik.DefaultTransport())
+}
+
+func BenchmarkSendMessage_HttpDefaultTransport(b *testing.B) {
+	benchmarkSendMessage(b, http.DefaultTransport.(*http.Transport).Clone())
+}
+
+func TestNewClient_Options(t *testing.T) {
+	var gotUserAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUserAgent = r.UserAgent()
+		fmt.Fprint(w, `{}`)
+	}))
+	defer server.Close()
+
+	httpClient := &http.Client{}
+	policy := kik.DefaultRetryPolicy()
+	limiter := kik.NewRateLimiter(10, 1)
+	client, err := kik.NewClient("bot", "key",
+		kik.WithBaseUrl(server.URL+"/"),
+		kik.WithHttpClient(httpClient),
+		kik.WithTimeout(time.Second),
+		kik.WithUserAgent("mybot/1.0"),
+		kik.WithRetryPolicy(policy),
+		kik.WithRateLimiter(limiter),
+		kik.WithBatchConcurrency(4),
+	)
+	if err != nil {
+		t.Fatalf("NewClient returned an error = %+v; expected no error", err)
+	}
+
+	if client.Client.Timeout != time.Second || httpClient.Timeout != 0 {
+		t.Errorf("WithTimeout should set the timeout on a copy of the http.Client")
+	}
+	if client.RetryPolicy != policy || client.RateLimiter != limiter || client.BatchConcurrency != 4 {
+		t.Errorf("NewClient() = %+v; want the configured options", client)
+	}
+	if _, err := client.GetUser(context.Background(), username); err != nil {
+		t.Fatalf("GetUser(%s) returned an error = %+v; expected no error", username, err)
+	}
+	if gotUserAgent != "mybot/1.0" {
+		t.Errorf("User-Agent = %s; want mybot/1.0", gotUserAgent)
+	}
+}
+
+func TestWithHeader(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header
+		fmt.Fprint(w, `{}`)
+	}))
+	defer server.Close()
+
+	client, err := kik.NewClient("bot", "key",
+		kik.WithBaseUrl(server.URL+"/"),
+		kik.WithHeader("X-Bot-Team", "growth"),
+		kik.WithHeader("X-Bot-Team", "support"),
+		kik.WithHeader("user-agent", "mybot/2.0 (ops@example.com)"),
+	)
+	if err != nil {
+		t.Fatalf("NewClient returned an error = %+v; expected no error", err)
+	}
+
+	if _, err := client.GetUser(context.Background(), username); err != nil {
+		t.Fatalf("GetUser(%s) returned an error = %+v; expected no error", username, err)
+	}
+	if teams := got["X-Bot-Team"]; len(teams) != 2 || teams[0] != "growth" || teams[1] != "support" {
+		t.Errorf("X-Bot-Team = %v; want both values", teams)
+	}
+	if ua := got.Get("User-Agent"); ua != "mybot/2.0 (ops@example.com)" {
+		t.Errorf("User-Agent = %s; want the header to replace DefaultUserAgent", ua)
+	}
+	if username, _, ok := (&http.Request{Header: got}).BasicAuth(); !ok || username != "bot" {
+		t.Errorf("Authorization = %s; want the credentials of the bot", got.Get("Authorization"))
+	}
+
+	if _, err := kik.NewClient("bot", "key", kik.WithHeader("authorization", "Bearer x")); err == nil {
+		t.Errorf("WithHeader(authorization) returned no error; want an error")
+	}
+}
+
+func TestWithBaseUrl_TrailingSlash(t *testing.T) {
+	_, err := kik.NewClient("bot", "key", kik.WithBaseUrl("https://api.kik.com"))
+
+	if err == nil {
+		t.Errorf("Expected an error for a base URL without a trailing slash")
+	}
+}
+
+func TestWithEndpointTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(100 * time.Millisecond):
+		}
+		fmt.Fprint(w, `{}`)
+	}))
+	defer server.Close()
+
+	client, err := kik.NewClient("bot", "key",
+		kik.WithBaseUrl(server.URL+"/"),
+		kik.WithEndpointTimeout(kik.SendMessageUrl, 10*time.Millisecond),
+		kik.WithEndpointTimeout(kik.EndpointBroadcast, time.Second),
+	)
+	if err != nil {
+		t.Fatalf("NewClient() returned an error = %+v; expected no error", err)
+	}
+	messages := []kik.Message{kik.TextMessage{SendMessage: kik.SendMessage{To: "laura", Type: "text"}, Body: "Hi"}}
+
+	start := time.Now()
+	err = client.SendMessage(context.Background(), messages)
+	if err == nil || time.Since(start) > 90*time.Millisecond {
+		t.Errorf("SendMessage() = %v after %v; want it to time out after 10ms", err, time.Since(start))
+	}
+	if err := client.BroadcastMessage(context.Background(), messages); err != nil {
+		t.Errorf("BroadcastMessage() returned an error = %+v; want its longer timeout to apply", err)
+	}
+}
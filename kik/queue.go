@@ -0,0 +1,421 @@
+package kik
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// QueuedMessage is an encoded message waiting in a QueueStore.
+type QueuedMessage struct {
+	Id   string `json:"id"`
+	Data []byte `json:"data"` // The message as sent to the Kik API.
+}
+
+// QueueStore persists the messages of a Queue, so messages not sent yet survive restarts.
+type QueueStore interface {
+	// Add stores a message that was enqueued.
+	Add(ctx context.Context, m QueuedMessage) error
+	// Pending returns the stored messages in the order they were added.
+	Pending(ctx context.Context) ([]QueuedMessage, error)
+	// Remove deletes a message once it was sent, or failed for good.
+	Remove(ctx context.Context, id string) error
+}
+
+// MemoryQueueStore is an in-memory QueueStore that is safe for concurrent use.
+// It does not survive restarts, it is the default of a Queue without a Store.
+type MemoryQueueStore struct {
+	mu       sync.Mutex
+	messages []QueuedMessage
+}
+
+// NewMemoryQueueStore returns an empty MemoryQueueStore.
+func NewMemoryQueueStore() *MemoryQueueStore {
+	return &MemoryQueueStore{}
+}
+
+func (s *MemoryQueueStore) Add(ctx context.Context, m QueuedMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = append(s.messages, m)
+	return nil
+}
+
+func (s *MemoryQueueStore) Pending(ctx context.Context) ([]QueuedMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]QueuedMessage(nil), s.messages...), nil
+}
+
+func (s *MemoryQueueStore) Remove(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, m := range s.messages {
+		if m.Id == id {
+			s.messages = append(s.messages[:i], s.messages[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// DefaultQueueRetryDelay is how long messages of a failed request wait before they are sent again,
+// if the RetryDelay of a Queue is not set.
+const DefaultQueueRetryDelay = 10 * time.Second
+
+// Queue sends messages asynchronously: Enqueue returns once a message is stored,
+// and a pool of workers sends the queued messages in batches with SendMessage, paced and retried by the Client.
+// A message is removed from the Store after it was sent, so delivery is at least once:
+// messages sent right before a crash are sent again after the restart. Messages without an Id get the Id
+// of their queue entry, so a Client with a Dedupe store skips those that were sent already, see WithDeduplication.
+type Queue struct {
+	Client  *Client
+	Store   QueueStore // Defaults to a MemoryQueueStore.
+	Workers int        // How many batches are sent at once, defaults to 1.
+
+	// RetryDelay is how long messages wait before they are sent again, after their request failed
+	// even though Kik did not reject them, defaults to DefaultQueueRetryDelay.
+	RetryDelay time.Duration
+
+	// OnError is called, if set, for each message that can not be sent, because Kik rejected it
+	// or it is invalid. It is removed from the Store and not retried afterwards.
+	OnError func(m Message, err error)
+
+	once    sync.Once
+	mu      sync.Mutex
+	pending []QueuedMessage
+	queued  map[string]bool // Ids of the messages pending or being sent.
+	wake    chan struct{}
+}
+
+// NewQueue returns a Queue sending with k, persisting messages in store.
+func NewQueue(k *Client, store QueueStore, workers int) *Queue {
+	return &Queue{Client: k, Store: store, Workers: workers}
+}
+
+func (q *Queue) init() {
+	q.once.Do(func() {
+		if q.Store == nil {
+			q.Store = NewMemoryQueueStore()
+		}
+		q.queued = make(map[string]bool)
+		q.wake = make(chan struct{}, 1)
+	})
+}
+
+// Enqueue stores m to be sent by the workers, it does not wait for m to be sent.
+// Only the message types of this package can be queued, others return a NotMessageTypeError.
+func (q *Queue) Enqueue(ctx context.Context, m Message) error {
+	q.init()
+	var data bytes.Buffer
+	if err := q.Client.codec().Encode(&data, m); err != nil {
+		return err
+	}
+	if _, err := decodeMessage(q.Client.codec(), data.Bytes()); err != nil {
+		return err
+	}
+
+	queued := QueuedMessage{Id: NewMessageId(), Data: data.Bytes()}
+	if err := q.Store.Add(ctx, queued); err != nil {
+		return err
+	}
+	q.push(queued)
+	return nil
+}
+
+// Len returns the number of messages waiting to be picked up by a worker.
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending)
+}
+
+// Run loads the messages left in the Store by a previous run, then sends queued messages until ctx is done.
+// Messages not sent by then stay in the Store. Run must not be called again before it returned.
+func (q *Queue) Run(ctx context.Context) error {
+	q.init()
+	stored, err := q.Store.Pending(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Messages enqueued while loading may be both in the Store and queued already.
+	q.mu.Lock()
+	var resumed []QueuedMessage
+	for _, m := range stored {
+		if !q.queued[m.Id] {
+			q.queued[m.Id] = true
+			resumed = append(resumed, m)
+		}
+	}
+	q.pending = append(resumed, q.pending...)
+	q.mu.Unlock()
+	q.signal()
+
+	workers := q.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.work(ctx)
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// push queues m, unless it is queued already.
+func (q *Queue) push(m QueuedMessage) {
+	q.mu.Lock()
+	if q.queued[m.Id] {
+		q.mu.Unlock()
+		return
+	}
+	q.queued[m.Id] = true
+	q.pending = append(q.pending, m)
+	q.mu.Unlock()
+	q.signal()
+}
+
+// requeue queues messages that were taken by a worker again, ahead of the others.
+func (q *Queue) requeue(messages []QueuedMessage) {
+	q.mu.Lock()
+	q.pending = append(append([]QueuedMessage(nil), messages...), q.pending...)
+	q.mu.Unlock()
+	q.signal()
+}
+
+func (q *Queue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// take removes up to max queued messages.
+func (q *Queue) take(max int) []QueuedMessage {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n := len(q.pending)
+	if n > max {
+		n = max
+	}
+	batch := append([]QueuedMessage(nil), q.pending[:n]...)
+	q.pending = q.pending[n:]
+	if len(q.pending) > 0 {
+		q.signal() // Let another worker pick up the rest.
+	}
+	return batch
+}
+
+func (q *Queue) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-q.wake:
+		}
+		for {
+			batch := q.take(MaxMessagesPerRequest)
+			if len(batch) == 0 {
+				break
+			}
+			q.send(ctx, batch)
+			if ctx.Err() != nil {
+				return
+			}
+		}
+	}
+}
+
+// send sends a batch of queued messages with SendMessage and removes the messages that were sent from the Store.
+// Messages Kik rejected fail, the others of a failed request are sent again after the RetryDelay.
+func (q *Queue) send(ctx context.Context, batch []QueuedMessage) {
+	var (
+		messages []Message
+		entries  []QueuedMessage // The queue entry of each message.
+	)
+	for _, queued := range batch {
+		m, err := decodeMessage(q.Client.codec(), queued.Data)
+		if err != nil {
+			q.fail(ctx, queued, nil, err)
+			continue
+		}
+		if m.header().Id == "" {
+			m = withHeader(m, func(s *SendMessage) { s.Id = queued.Id })
+		}
+		messages = append(messages, m)
+		entries = append(entries, queued)
+	}
+	if len(messages) == 0 {
+		return
+	}
+
+	chunks, err := q.Client.sendMessages(ctx, messages)
+	if err != nil && ctx.Err() != nil {
+		q.forget(batch) // Shutting down, the messages stay in the Store for the next run.
+		return
+	}
+
+	var validationErr *ValidationError
+	rejected, failed := map[string]error{}, map[string]bool{}
+	switch {
+	case err == nil:
+	case errors.As(err, &validationErr):
+		// Nothing was sent, the other messages are sent again right away.
+		rejected[messages[validationErr.Index].header().Id] = err
+		for i, m := range messages {
+			if i != validationErr.Index {
+				failed[m.header().Id] = true
+			}
+		}
+	default:
+		failedChunks := failedMessages(chunks, err)
+		if len(failedChunks) == 0 {
+			for _, m := range messages {
+				failed[m.header().Id] = true
+			}
+		}
+		for _, f := range failedChunks {
+			if f.Rejected {
+				rejected[f.Message.header().Id] = f.Err
+			} else {
+				failed[f.Message.header().Id] = true
+			}
+		}
+	}
+
+	var retry, again []QueuedMessage
+	for i, m := range messages {
+		id := m.header().Id
+		switch {
+		case rejected[id] != nil:
+			q.fail(ctx, entries[i], m, rejected[id])
+		case failed[id] && validationErr != nil:
+			again = append(again, entries[i])
+		case failed[id]:
+			// The Dedupe store recorded the message as sent, it would be skipped when it is sent again.
+			if q.Client.Dedupe != nil {
+				q.Client.Dedupe.Forget("message:" + id)
+			}
+			retry = append(retry, entries[i])
+		default:
+			q.remove(ctx, entries[i])
+		}
+	}
+	if len(again) > 0 {
+		q.requeue(again)
+	}
+	if len(retry) > 0 {
+		q.Client.debug("kik queue retrying messages", "count", len(retry), "error", err)
+		time.AfterFunc(q.retryDelay(), func() { q.requeue(retry) })
+	}
+}
+
+// failedMessages returns the messages of the requests that failed with err, see BatchError.Failed.
+// Kik names the messages it rejects. If it rejected a request with a 400 without naming any,
+// all of its messages are marked Rejected.
+func failedMessages(chunks []chunk, err error) []FailedMessage {
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) {
+		if len(chunks) != 1 {
+			return nil
+		}
+		c := chunks[0]
+		batchErr = &BatchError{Errors: []*ChunkError{{Offset: c.offset, Count: len(c.messages), Messages: c.messages, Err: err}}}
+	}
+
+	var failed []FailedMessage
+	for _, chunkErr := range batchErr.Errors {
+		chunkFailed := (&BatchError{Errors: []*ChunkError{chunkErr}}).Failed()
+		named := false
+		for _, f := range chunkFailed {
+			named = named || f.Rejected
+		}
+		var apiErr *APIError
+		if !named && errors.As(chunkErr.Err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest {
+			for i := range chunkFailed {
+				chunkFailed[i].Rejected, chunkFailed[i].Reason = true, apiErr.Message
+			}
+		}
+		failed = append(failed, chunkFailed...)
+	}
+	return failed
+}
+
+func (q *Queue) retryDelay() time.Duration {
+	if q.RetryDelay <= 0 {
+		return DefaultQueueRetryDelay
+	}
+	return q.RetryDelay
+}
+
+// remove deletes a message that was sent, or failed for good, from the Store.
+func (q *Queue) remove(ctx context.Context, queued QueuedMessage) {
+	if err := q.Store.Remove(ctx, queued.Id); err != nil {
+		q.Client.debug("kik queue remove failed", "id", queued.Id, "error", err)
+	}
+	q.forget([]QueuedMessage{queued})
+}
+
+// forget lets messages that are no longer being sent be queued again, e.g. by the next Run.
+func (q *Queue) forget(messages []QueuedMessage) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, m := range messages {
+		delete(q.queued, m.Id)
+	}
+}
+
+func (q *Queue) fail(ctx context.Context, queued QueuedMessage, m Message, err error) {
+	if q.OnError != nil {
+		q.OnError(m, err)
+	}
+	q.remove(ctx, queued)
+}
+
+// decodeMessage decodes an outgoing message into the type matching its "type" field.
+func decodeMessage(codec Codec, data []byte) (Message, error) {
+	var typed struct {
+		Type string `json:"type"`
+	}
+	if err := unmarshal(codec, data, &typed); err != nil {
+		return nil, err
+	}
+
+	switch typed.Type {
+	case "text":
+		var t TextMessage
+		err := unmarshal(codec, data, &t)
+		return t, err
+	case "picture":
+		var p PictureMessage
+		err := unmarshal(codec, data, &p)
+		return p, err
+	case "link":
+		var l LinkMessage
+		err := unmarshal(codec, data, &l)
+		return l, err
+	case "video":
+		var v VideoMessage
+		err := unmarshal(codec, data, &v)
+		return v, err
+	case "is-typing":
+		var t IsTypingMessage
+		err := unmarshal(codec, data, &t)
+		return t, err
+	case "read-receipt":
+		var r ReadReceiptMessage
+		err := unmarshal(codec, data, &r)
+		return r, err
+	}
+	return nil, fmt.Errorf("%w: %q", NotMessageTypeError, typed.Type)
+}
@@ -0,0 +1,324 @@
+package kik_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/r-kells/go-kik/kik"
+	"github.com/r-kells/go-kik/kiktest"
+)
+
+// waitFor polls cond until it is true, or fails the test after a second.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func runQueue(q *kik.Queue) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		q.Run(ctx)
+	}()
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+func TestQueue_Sends(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+
+	store := kik.NewMemoryQueueStore()
+	q := kik.NewQueue(server.Client, store, 2)
+	stop := runQueue(q)
+	defer stop()
+
+	for _, body := range []string{"one", "two", "three"} {
+		m := kik.TextMessage{SendMessage: kik.SendMessage{To: username, Type: "text"}, Body: body}
+		if err := q.Enqueue(context.Background(), m); err != nil {
+			t.Fatalf("Enqueue(%s) returned an error = %+v; expected no error", body, err)
+		}
+	}
+
+	waitFor(t, "3 messages", func() bool { return len(server.Messages()) == 3 })
+	waitFor(t, "an empty store", func() bool {
+		pending, _ := store.Pending(context.Background())
+		return len(pending) == 0
+	})
+}
+
+func TestQueue_ResumesStored(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+
+	// A previous run enqueued a message but stopped before sending it.
+	store := kik.NewMemoryQueueStore()
+	stopped := kik.NewQueue(server.Client, store, 1)
+	m := kik.TextMessage{SendMessage: kik.SendMessage{To: username, Type: "text"}, Body: "left over"}
+	if err := stopped.Enqueue(context.Background(), m); err != nil {
+		t.Fatalf("Enqueue() returned an error = %+v; expected no error", err)
+	}
+
+	q := kik.NewQueue(server.Client, store, 1)
+	stop := runQueue(q)
+	defer stop()
+
+	waitFor(t, "the stored message", func() bool { return len(server.Messages()) == 1 })
+	if sent := server.Messages(); sent[0].Body != "left over" || sent[0].Type != "text" {
+		t.Errorf("Run() sent %+v; want the stored text message", sent)
+	}
+}
+
+func TestQueue_OnError(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+	server.Fail(kik.SendMessageUrl, http.StatusBadRequest, 1)
+
+	store := kik.NewMemoryQueueStore()
+	q := kik.NewQueue(server.Client, store, 1)
+	failed := make(chan kik.Message, 1)
+	q.OnError = func(m kik.Message, err error) { failed <- m }
+	stop := runQueue(q)
+	defer stop()
+
+	m := kik.LinkMessage{SendMessage: kik.SendMessage{To: username, Type: "link"}, Url: "https://example.com"}
+	if err := q.Enqueue(context.Background(), m); err != nil {
+		t.Fatalf("Enqueue() returned an error = %+v; expected no error", err)
+	}
+
+	select {
+	case got := <-failed:
+		if link, ok := got.(kik.LinkMessage); !ok || link.Url != m.Url {
+			t.Errorf("OnError got %#v; want the link message", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("OnError was not called")
+	}
+	waitFor(t, "an empty store", func() bool {
+		pending, _ := store.Pending(context.Background())
+		return len(pending) == 0
+	})
+}
+
+func TestQueue_RetriesMessagesNotRejected(t *testing.T) {
+	client, mux, teardown := kiktest.TestClient(t)
+	defer teardown()
+
+	var (
+		mu       sync.Mutex
+		requests int
+		sent     []string
+	)
+	mux.HandleFunc(kik.SendMessageUrl, func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Messages []struct{ Body string } `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&payload)
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		switch requests {
+		case 1:
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error": "BadRequest", "message": "messages[1].body: is invalid"}`)
+			return
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		for _, m := range payload.Messages {
+			sent = append(sent, m.Body)
+		}
+	})
+
+	store := kik.NewMemoryQueueStore()
+	q := kik.NewQueue(client, store, 1)
+	q.RetryDelay = time.Millisecond
+	failed := make(chan kik.Message, 3)
+	q.OnError = func(m kik.Message, err error) { failed <- m }
+
+	for _, body := range []string{"one", "two", "three"} {
+		m := kik.TextMessage{SendMessage: kik.SendMessage{To: username, Type: "text"}, Body: body}
+		if err := q.Enqueue(context.Background(), m); err != nil {
+			t.Fatalf("Enqueue(%s) returned an error = %+v; expected no error", body, err)
+		}
+	}
+	stop := runQueue(q)
+	defer stop()
+
+	waitFor(t, "an empty store", func() bool {
+		pending, _ := store.Pending(context.Background())
+		return len(pending) == 0
+	})
+	mu.Lock()
+	defer mu.Unlock()
+	if fmt.Sprint(sent) != "[one three]" {
+		t.Errorf("Queue sent %v; want the messages Kik did not reject", sent)
+	}
+	if len(failed) != 1 {
+		t.Fatalf("OnError called %d times; want once for the rejected message", len(failed))
+	}
+	if m := <-failed; m.(kik.TextMessage).Body != "two" {
+		t.Errorf("OnError got %+v; want the rejected message", m)
+	}
+}
+
+func TestQueue_SkipsSentWithDedupe(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+	server.Client.Dedupe = kik.NewMemoryDedupeStore()
+
+	// The process crashed after sending the message, before removing it from the Store.
+	store := kik.NewMemoryQueueStore()
+	stopped := kik.NewQueue(server.Client, store, 1)
+	m := kik.TextMessage{SendMessage: kik.SendMessage{To: username, Type: "text"}, Body: "once"}
+	if err := stopped.Enqueue(context.Background(), m); err != nil {
+		t.Fatalf("Enqueue() returned an error = %+v; expected no error", err)
+	}
+	pending, _ := store.Pending(context.Background())
+
+	for i := 0; i < 2; i++ {
+		if i > 0 {
+			store.Add(context.Background(), pending[0])
+		}
+		stop := runQueue(kik.NewQueue(server.Client, store, 1))
+		waitFor(t, "an empty store", func() bool {
+			pending, _ := store.Pending(context.Background())
+			return len(pending) == 0
+		})
+		stop()
+	}
+
+	sent := server.Messages()
+	if len(sent) != 1 {
+		t.Fatalf("Queue sent %+v; want the message once", sent)
+	}
+	var got kik.TextMessage
+	if err := sent[0].Decode(&got); err != nil || got.Id != pending[0].Id {
+		t.Errorf("Queue sent %s; want it with the id %s of its queue entry", sent[0].Raw, pending[0].Id)
+	}
+}
+
+// racingStore enqueues a message to the Queue while Run loads the Store.
+type racingStore struct {
+	*kik.MemoryQueueStore
+	once    sync.Once
+	enqueue func()
+}
+
+func (s *racingStore) Pending(ctx context.Context) ([]kik.QueuedMessage, error) {
+	s.once.Do(s.enqueue)
+	return s.MemoryQueueStore.Pending(ctx)
+}
+
+func TestQueue_EnqueueWhileLoading(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+
+	store := &racingStore{MemoryQueueStore: kik.NewMemoryQueueStore()}
+	q := kik.NewQueue(server.Client, store, 2)
+	done := make(chan struct{})
+	store.enqueue = func() {
+		go func() {
+			defer close(done)
+			q.Enqueue(context.Background(), kik.TextMessage{SendMessage: kik.SendMessage{To: username, Type: "text"}, Body: "Hi"})
+		}()
+		// Once the message is stored, it may be queued before or after the Store is loaded.
+		waitFor(t, "the stored message", func() bool {
+			pending, _ := store.MemoryQueueStore.Pending(context.Background())
+			return len(pending) > 0
+		})
+	}
+	stop := runQueue(q)
+	defer stop()
+
+	<-done
+	waitFor(t, "an empty store", func() bool {
+		pending, _ := store.Pending(context.Background())
+		return len(pending) == 0
+	})
+	time.Sleep(20 * time.Millisecond)
+	if sent := server.Messages(); len(sent) != 1 {
+		t.Errorf("Queue sent %d messages; want 1", len(sent))
+	}
+}
+
+func TestQueue_RetriesNetworkErrorsWithDedupe(t *testing.T) {
+	client, mux, teardown := kiktest.TestClient(t)
+	defer teardown()
+	client.Dedupe = kik.NewMemoryDedupeStore()
+
+	var (
+		mu       sync.Mutex
+		requests int
+		sent     []string
+	)
+	mux.HandleFunc(kik.SendMessageUrl, func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Messages []struct{ Body string } `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&payload)
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		if requests == 1 {
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+		for _, m := range payload.Messages {
+			sent = append(sent, m.Body)
+		}
+	})
+
+	store := kik.NewMemoryQueueStore()
+	q := kik.NewQueue(client, store, 1)
+	q.RetryDelay = time.Millisecond
+	m := kik.TextMessage{SendMessage: kik.SendMessage{To: username, Type: "text"}, Body: "again"}
+	if err := q.Enqueue(context.Background(), m); err != nil {
+		t.Fatalf("Enqueue() returned an error = %+v; expected no error", err)
+	}
+	stop := runQueue(q)
+	defer stop()
+
+	waitFor(t, "an empty store", func() bool {
+		pending, _ := store.Pending(context.Background())
+		return len(pending) == 0
+	})
+	mu.Lock()
+	defer mu.Unlock()
+	if fmt.Sprint(sent) != "[again]" {
+		t.Errorf("Queue sent %v after %d requests; want the message sent by the retry", sent, requests)
+	}
+}
+
+func TestQueue_EnqueueRejectsUnknownTypes(t *testing.T) {
+	client, _, teardown := kiktest.TestClient(t)
+	defer teardown()
+
+	store := kik.NewMemoryQueueStore()
+	q := kik.NewQueue(client, store, 1)
+	m := poll{SendMessage: kik.SendMessage{To: username, Type: "poll"}, Question: "?"}
+	if err := q.Enqueue(context.Background(), m); !errors.Is(err, kik.NotMessageTypeError) {
+		t.Errorf("Enqueue(poll) returned %v; want %v", err, kik.NotMessageTypeError)
+	}
+	if pending, _ := store.Pending(context.Background()); len(pending) != 0 {
+		t.Errorf("Store has %d messages; want none", len(pending))
+	}
+}
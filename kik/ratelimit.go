@@ -0,0 +1,74 @@
+package kik
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limits documented by Kik for sending messages.
+const (
+	MaxMessagesPerRequest = 25 // The maximum number of messages a single SendMessage request may contain.
+	MaxMessagesPerUser    = 5  // The maximum number of messages to a single user a request may contain.
+	MaxBroadcastMessages  = 25 // The maximum number of messages a single BroadcastMessage request may contain.
+)
+
+// RateLimiter paces requests to the Kik API.
+type RateLimiter interface {
+	// Wait blocks until a request may be sent, or returns the context's error if ctx is done first.
+	Wait(ctx context.Context) error
+}
+
+// TokenBucket is a RateLimiter allowing bursts of requests, refilled at a steady rate.
+// It is safe for concurrent use, waiting callers are served in the order they arrived.
+type TokenBucket struct {
+	rate  float64 // Tokens added per second.
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64 // Negative when callers are waiting for tokens.
+	last   time.Time
+}
+
+// NewRateLimiter returns a TokenBucket allowing perSecond requests on average and up to burst requests at once.
+// A perSecond of 0 or less does not limit requests.
+func NewRateLimiter(perSecond float64, burst int) *TokenBucket {
+	return &TokenBucket{
+		rate:   perSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	if !(b.rate > 0) { // Also catches NaN, the wait for a token would not be a number.
+		return nil
+	}
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+
+	// Reserve a token, waiting for it to be refilled if there is none left.
+	b.tokens--
+	var wait time.Duration
+	if b.tokens < 0 {
+		wait = time.Duration(-b.tokens / b.rate * float64(time.Second))
+	}
+	b.mu.Unlock()
+
+	if wait == 0 {
+		return nil
+	}
+	if err := sleep(ctx, wait); err != nil {
+		b.mu.Lock()
+		b.tokens++
+		b.mu.Unlock()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package kik_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/r-kells/go-kik/kik"
+	"github.com/r-kells/go-kik/kiktest"
+)
+
+func TestRateLimiter_PacesSendMessage(t *testing.T) {
+	client, mux, teardown := kiktest.TestClient(t)
+	defer teardown()
+	client.RateLimiter = kik.NewRateLimiter(50, 2)
+
+	mux.HandleFunc(kik.SendMessageUrl, func(w http.ResponseWriter, r *http.Request) {})
+
+	messages := []kik.Message{
+		kik.TextMessage{SendMessage: kik.SendMessage{To: username, Type: "text"}, Body: "Hi"},
+	}
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 7; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := client.SendMessage(context.Background(), messages); err != nil {
+				t.Errorf("SendMessage() returned an error = %+v; expected no error", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The first 2 are sent in a burst, the other 5 are paced 20ms apart.
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("sent 7 requests in %v; want at least 100ms at 50 requests/second", elapsed)
+	}
+}
+
+func TestRateLimiter_ContextDone(t *testing.T) {
+	limiter := kik.NewRateLimiter(1, 1)
+	limiter.Wait(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := limiter.Wait(ctx)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	// The cancelled wait gave its token back, so the next one only waits for the first refill.
+	start := time.Now()
+	limiter.Wait(context.Background())
+	if elapsed := time.Since(start); elapsed > 1500*time.Millisecond {
+		t.Errorf("waited %v after a cancelled wait; want about 1s", elapsed)
+	}
+}
+
+func TestRateLimiter_ZeroRate(t *testing.T) {
+	for _, rate := range []float64{0, -1} {
+		limiter := kik.NewRateLimiter(rate, 1)
+		start := time.Now()
+		for i := 0; i < 10; i++ {
+			if err := limiter.Wait(context.Background()); err != nil {
+				t.Fatalf("Wait() at rate %v returned an error = %+v; expected no error", rate, err)
+			}
+		}
+		if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+			t.Errorf("10 waits at rate %v took %v; want no limit", rate, elapsed)
+		}
+	}
+}
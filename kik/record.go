@@ -0,0 +1,163 @@
+package kik
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RecordedWebhook is a webhook request as Kik sent it, see Recorder.
+type RecordedWebhook struct {
+	Time      time.Time `json:"time"`
+	Path      string    `json:"path"`
+	Signature string    `json:"signature"` // The SignatureHeader of the request.
+	Body      string    `json:"body"`
+}
+
+// Request returns a request delivering the webhook again to url, signed like the original.
+func (w RecordedWebhook) Request(ctx context.Context, url string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(w.Body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, w.Signature)
+	return req, nil
+}
+
+// Recorder is an http.Handler writing every webhook request it receives to W, as a line of JSON, before serving it with Next.
+// Recordings can be replayed with Replay, or with the replay command of kikctl, to reproduce what a user did.
+// They contain the messages of users and valid signatures, so they must be stored like other user data.
+type Recorder struct {
+	W    io.Writer
+	Next http.Handler
+
+	// OnError is called, if set, when a request can not be recorded, it is served regardless.
+	OnError func(r *http.Request, err error)
+
+	mu sync.Mutex // serializes writes, so concurrent requests are not interleaved.
+}
+
+// NewRecorder returns a Recorder writing webhooks for next to w.
+func NewRecorder(w io.Writer, next http.Handler) *Recorder {
+	return &Recorder{W: w, Next: next}
+}
+
+func (rec *Recorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		if err := rec.record(r); err != nil && rec.OnError != nil {
+			rec.OnError(r, err)
+		}
+	}
+	rec.Next.ServeHTTP(w, r)
+}
+
+func (rec *Recorder) record(r *http.Request) error {
+	// A body over the limit is passed on with a byte past it, so the next handler rejects it too.
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, MaxWebhookBodySize+1))
+	r.Body.Close()
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if len(body) > MaxWebhookBodySize {
+		return BodyTooLargeError
+	}
+
+	line, err := json.Marshal(RecordedWebhook{
+		Time:      time.Now(),
+		Path:      r.URL.Path,
+		Signature: r.Header.Get(SignatureHeader),
+		Body:      string(body),
+	})
+	if err != nil {
+		return err
+	}
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	_, err = rec.W.Write(append(line, '\n'))
+	return err
+}
+
+// ReadRecording returns the webhooks written by a Recorder, in the order they were received.
+func ReadRecording(r io.Reader) ([]RecordedWebhook, error) {
+	var webhooks []RecordedWebhook
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 2*MaxWebhookBodySize) // Escaping may grow the body.
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var w RecordedWebhook
+		if err := json.Unmarshal(scanner.Bytes(), &w); err != nil {
+			return nil, fmt.Errorf("recording line %d: %w", line, err)
+		}
+		webhooks = append(webhooks, w)
+	}
+	return webhooks, scanner.Err()
+}
+
+// Replay delivers the webhooks of a recording to h again, in order, e.g. to a Bot or WebhookHandler in a test.
+// The requests carry the recorded signatures, so h must verify them with the API key they were recorded with.
+// It stops at the first webhook h does not respond to with a 2xx status.
+func Replay(ctx context.Context, recording io.Reader, h http.Handler) error {
+	webhooks, err := ReadRecording(recording)
+	if err != nil {
+		return err
+	}
+	for i, w := range webhooks {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		path := w.Path
+		if path == "" {
+			path = "/"
+		}
+		req, err := w.Request(ctx, "http://replay"+path)
+		if err != nil {
+			return err
+		}
+
+		resp := newResponseRecorder()
+		h.ServeHTTP(resp, req)
+		if resp.code < 200 || resp.code > 299 {
+			return fmt.Errorf("replaying webhook %d recorded at %v: status %d: %s",
+				i, w.Time.Format(time.RFC3339), resp.code, strings.TrimSpace(resp.body.String()))
+		}
+	}
+	return nil
+}
+
+// responseRecorder is an http.ResponseWriter keeping the response of a replayed webhook.
+type responseRecorder struct {
+	code        int
+	header      http.Header
+	body        bytes.Buffer
+	wroteHeader bool
+}
+
+func newResponseRecorder() *responseRecorder {
+	return &responseRecorder{code: http.StatusOK, header: make(http.Header)}
+}
+
+func (r *responseRecorder) Header() http.Header { return r.header }
+
+func (r *responseRecorder) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.code, r.wroteHeader = code, true
+	}
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.WriteHeader(http.StatusOK)
+	return r.body.Write(b)
+}
@@ -0,0 +1,73 @@
+package kik_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/r-kells/go-kik/kik"
+	"github.com/r-kells/go-kik/kiktest"
+)
+
+func TestRecorder_Replay(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+
+	var got []string
+	handler := kik.NewWebhookHandler(server.Client, func(ctx context.Context, m kik.Receive) error {
+		got = append(got, m.(*kik.TextMessageReceive).Body)
+		return nil
+	})
+
+	var recording bytes.Buffer
+	recorder := kik.NewRecorder(&recording, handler)
+	server.Webhook(recorder, textFrom("laura", "c1", "Hi"))
+	server.Webhook(recorder, textFrom("laura", "c1", "Are you there?"))
+
+	webhooks, err := kik.ReadRecording(bytes.NewReader(recording.Bytes()))
+	if err != nil || len(webhooks) != 2 || webhooks[0].Signature == "" || webhooks[0].Path != "/" {
+		t.Fatalf("ReadRecording() = %+v, %v; want both signed webhooks", webhooks, err)
+	}
+
+	if err := kik.Replay(context.Background(), bytes.NewReader(recording.Bytes()), handler); err != nil {
+		t.Fatalf("Replay() returned an error = %+v; expected no error", err)
+	}
+	want := []string{"Hi", "Are you there?", "Hi", "Are you there?"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("dispatched %v; want %v", got, want)
+	}
+}
+
+func TestReplay_InvalidSignature(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+
+	handler := kik.NewWebhookHandler(server.Client, func(ctx context.Context, m kik.Receive) error {
+		t.Errorf("Handler should not be called for a tampered webhook")
+		return nil
+	})
+	recording := `{"path": "/", "signature": "0000", "body": "{\"messages\": []}"}` + "\n"
+
+	err := kik.Replay(context.Background(), strings.NewReader(recording), handler)
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("Replay() = %v; want an error with status 403", err)
+	}
+	if _, err := kik.ReadRecording(strings.NewReader("not json\n")); err == nil {
+		t.Errorf("ReadRecording() of garbage returned no error; want an error")
+	}
+}
+
+func TestRecorder_OnlyPosts(t *testing.T) {
+	var recording bytes.Buffer
+	recorder := kik.NewRecorder(&recording, http.NotFoundHandler())
+
+	rec := httptest.NewRecorder()
+	recorder.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusNotFound || recording.Len() != 0 {
+		t.Errorf("GET = %d, recorded %q; want it served by the next handler and not recorded", rec.Code, recording.String())
+	}
+}
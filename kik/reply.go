@@ -0,0 +1,70 @@
+package kik
+
+import "context"
+
+// ReplyText returns a text message with body to the sender of m, in the same chat.
+func (m ReceiveMessage) ReplyText(body string) TextMessage {
+	return TextMessage{SendMessage: m.replyHeader("text"), Body: body}
+}
+
+// ReplyPicture returns a picture message of picUrl to the sender of m, in the same chat.
+func (m ReceiveMessage) ReplyPicture(picUrl string) PictureMessage {
+	return PictureMessage{SendMessage: m.replyHeader("picture"), PicUrl: picUrl}
+}
+
+// ReplyLink returns a link message of url to the sender of m, in the same chat.
+func (m ReceiveMessage) ReplyLink(url string) LinkMessage {
+	return LinkMessage{SendMessage: m.replyHeader("link"), Url: url}
+}
+
+// ReplyVideo returns a video message of videoUrl to the sender of m, in the same chat.
+func (m ReceiveMessage) ReplyVideo(videoUrl string) VideoMessage {
+	return VideoMessage{SendMessage: m.replyHeader("video"), VideoUrl: videoUrl}
+}
+
+// Reply returns a copy of message sent to the sender of m, in the same chat.
+// This sets To and ChatId of any message type, the Type still has to be set.
+func (m ReceiveMessage) Reply(message Message) Message {
+	return addressed(message, m.From, m.ChatId)
+}
+
+func (m ReceiveMessage) replyHeader(messageType string) SendMessage {
+	return SendMessage{To: m.From, Type: messageType, ChatId: m.ChatId}
+}
+
+// Reply sends messages to the sender of incoming, in the same chat, see ReceiveMessage.Reply.
+func (k *Client) Reply(ctx context.Context, incoming Receive, messages ...Message) error {
+	from := incoming.header()
+	replies := make([]Message, len(messages))
+	for i, m := range messages {
+		replies[i] = from.Reply(m)
+	}
+	return k.SendMessage(ctx, replies)
+}
+
+// ReplyRichDelay is the pause in milliseconds between the typing indicator and the text sent by ReplyRich.
+const ReplyRichDelay = 1500
+
+// ReplyRich builds the common complete reply to an incoming message:
+// the bot appears to type, pauses for ReplyRichDelay, then sends body with a suggested response for each of responses.
+// In group chats the keyboard is only shown to the sender of the incoming message.
+func ReplyRich(incoming Receive, body string, responses ...string) []Message {
+	m := incoming.header()
+
+	typing := IsTypingMessage{
+		SendMessage: m.replyHeader("is-typing"),
+		IsTyping:    true,
+	}
+	text := m.ReplyText(body)
+	text.Delay = ReplyRichDelay
+
+	if len(responses) > 0 {
+		keyboard := NewKeyboard().WithTextResponses(responses...)
+		if m.IsGroupChat() {
+			keyboard.To(m.From)
+		}
+		text.Keyboards = []SuggestedResponseKeyboard{keyboard.Build()}
+	}
+
+	return []Message{typing, text}
+}
@@ -0,0 +1,158 @@
+package kik_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/r-kells/go-kik/kik"
+	"github.com/r-kells/go-kik/kiktest"
+)
+
+func TestReplyRich_Direct(t *testing.T) {
+	incoming := &kik.TextMessageReceive{
+		ReceiveMessage: kik.ReceiveMessage{
+			ChatId:       "0ee6d46753bfa6ac2f089149959363f3f59ae62b10cba89cc426490ce38ea92d",
+			From:         "laura",
+			Type:         "text",
+			Participants: []string{"laura"},
+			ChatType:     "direct",
+		},
+		Body: "Hi",
+	}
+
+	got := kik.ReplyRich(incoming, "Want to play?", "Yes", "No")
+
+	want := []kik.Message{
+		kik.IsTypingMessage{
+			SendMessage: kik.SendMessage{To: "laura", Type: "is-typing", ChatId: incoming.ChatId},
+			IsTyping:    true,
+		},
+		kik.TextMessage{
+			SendMessage: kik.SendMessage{
+				To:     "laura",
+				Type:   "text",
+				ChatId: incoming.ChatId,
+				Delay:  kik.ReplyRichDelay,
+				Keyboards: []kik.SuggestedResponseKeyboard{{
+					Type: "suggested",
+					Responses: []kik.SuggestedResponse{
+						kik.KeyboardTextResponse{Type: "text", Body: "Yes"},
+						kik.KeyboardTextResponse{Type: "text", Body: "No"},
+					},
+				}},
+			},
+			Body: "Want to play?",
+		},
+	}
+	if !cmp.Equal(got, want) {
+		t.Errorf("ReplyRich() = %v; want %v", got, want)
+	}
+}
+
+func TestReplyRich_Group(t *testing.T) {
+	tests := []kik.ReceiveMessage{
+		{ChatId: "group", From: "laura", Type: "text", ChatType: "public"},
+		{ChatId: "group", From: "laura", Type: "text", ChatType: "private"},
+		// Older payloads without a chatType.
+		{ChatId: "group", From: "laura", Type: "text", Participants: []string{"laura", "kikteam"}},
+	}
+	for _, received := range tests {
+		got := kik.ReplyRich(&kik.TextMessageReceive{ReceiveMessage: received}, "Pick one", "A")
+
+		text := got[1].(kik.TextMessage)
+		if text.To != "laura" || text.ChatId != "group" {
+			t.Errorf("ReplyRich(%+v) sent to %s in %s; want laura in group", received, text.To, text.ChatId)
+		}
+		if text.Keyboards[0].To != "laura" {
+			t.Errorf("ReplyRich(%+v) keyboard is shown to %q; want only laura", received, text.Keyboards[0].To)
+		}
+	}
+}
+
+func TestReplyRich_NoResponses(t *testing.T) {
+	got := kik.ReplyRich(&kik.TextMessageReceive{ReceiveMessage: kik.ReceiveMessage{From: "laura"}}, "Hi")
+
+	if text := got[1].(kik.TextMessage); text.Keyboards != nil {
+		t.Errorf("ReplyRich() keyboards = %v; want none", text.Keyboards)
+	}
+}
+
+func TestReplyHelpers(t *testing.T) {
+	incoming := &kik.PictureMessageReceive{
+		ReceiveMessage: kik.ReceiveMessage{ChatId: "c1", From: "laura", Type: "picture"},
+	}
+	header := kik.SendMessage{To: "laura", ChatId: "c1"}
+
+	tests := []struct {
+		got  kik.Message
+		want kik.Message
+	}{
+		{incoming.ReplyText("Hi"), kik.TextMessage{SendMessage: withType(header, "text"), Body: "Hi"}},
+		{incoming.ReplyPicture("http://pic"), kik.PictureMessage{SendMessage: withType(header, "picture"), PicUrl: "http://pic"}},
+		{incoming.ReplyLink("http://link"), kik.LinkMessage{SendMessage: withType(header, "link"), Url: "http://link"}},
+		{incoming.ReplyVideo("http://video"), kik.VideoMessage{SendMessage: withType(header, "video"), VideoUrl: "http://video"}},
+		{
+			incoming.Reply(kik.IsTypingMessage{SendMessage: kik.SendMessage{To: "other", Type: "is-typing"}, IsTyping: true}),
+			kik.IsTypingMessage{SendMessage: withType(header, "is-typing"), IsTyping: true},
+		},
+		{
+			incoming.Reply(&kik.TextMessage{SendMessage: kik.SendMessage{Type: "text"}, Body: "Hi"}),
+			&kik.TextMessage{SendMessage: withType(header, "text"), Body: "Hi"},
+		},
+		{
+			incoming.Reply(poll{SendMessage: kik.SendMessage{Type: "poll"}, Question: "Why?"}),
+			poll{SendMessage: withType(header, "poll"), Question: "Why?"},
+		},
+		{
+			incoming.Reply(&tagged{Message: kik.TextMessage{SendMessage: kik.SendMessage{Type: "text"}, Body: "Hi"}, Tag: "a"}),
+			&tagged{Message: kik.TextMessage{SendMessage: withType(header, "text"), Body: "Hi"}, Tag: "a"},
+		},
+	}
+	for _, test := range tests {
+		if diff := cmp.Diff(test.want, test.got); diff != "" {
+			t.Errorf("reply mismatch (-want +got):\n%s", diff)
+		}
+	}
+}
+
+func TestClientReply(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+
+	incoming := &kik.TextMessageReceive{ReceiveMessage: kik.ReceiveMessage{ChatId: "c1", From: "laura", Type: "text"}}
+	err := server.Client.Reply(context.Background(), incoming,
+		kik.TextMessage{SendMessage: kik.SendMessage{Type: "text"}, Body: "Hi"},
+		incoming.ReplyPicture("http://pic"),
+	)
+	if err != nil {
+		t.Fatalf("Reply() returned an error = %+v; expected no error", err)
+	}
+
+	sent := server.Messages()
+	if len(sent) != 2 {
+		t.Fatalf("Reply() sent %d messages; want 2", len(sent))
+	}
+	for _, m := range sent {
+		if m.To != "laura" || m.ChatId != "c1" {
+			t.Errorf("Reply() sent %+v; want it to laura in c1", m)
+		}
+	}
+}
+
+// poll is a message type of another package.
+type poll struct {
+	kik.SendMessage
+	Question string `json:"question"`
+}
+
+// tagged wraps a message of this package.
+type tagged struct {
+	kik.Message
+	Tag string
+}
+
+func withType(s kik.SendMessage, messageType string) kik.SendMessage {
+	s.Type = messageType
+	return s
+}
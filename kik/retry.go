@@ -0,0 +1,125 @@
+package kik
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how failed requests to the Kik API are retried.
+type RetryPolicy struct {
+	MaxAttempts int           // The total number of attempts, including the first one.
+	Backoff     time.Duration // The delay before the first retry, doubled for every retry after.
+	MaxBackoff  time.Duration // Caps the delay between retries, unless the API asks for longer with Retry-After.
+	Jitter      float64       // Randomizes each delay by up to this fraction, between 0 and 1.
+
+	RetryableStatusCodes []int // Responses with these status codes are retried.
+
+	// RetryNetworkErrors makes requests that failed without a response be retried. Kik may have received
+	// such a request, so sending messages again could deliver them twice: sends are only retried if the Client
+	// has a Dedupe store and every message has an Id, see AssignMessageIds, and broadcasts and other POST
+	// requests except setting the configuration are not retried.
+	RetryNetworkErrors bool
+}
+
+// DefaultRetryPolicy returns a RetryPolicy retrying rate limited requests and server errors up to 3 times.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 4,
+		Backoff:     250 * time.Millisecond,
+		MaxBackoff:  5 * time.Second,
+		Jitter:      0.2,
+		RetryableStatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+		RetryNetworkErrors: true,
+	}
+}
+
+// retry reports whether the request should be attempted again after attempt failed with err, and how long to wait first.
+func (p *RetryPolicy) retry(ctx context.Context, attempt int, err error) (time.Duration, bool) {
+	if p == nil || attempt >= p.MaxAttempts || ctx.Err() != nil {
+		return 0, false
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return p.backoff(attempt), p.RetryNetworkErrors
+	}
+
+	if !p.retryable(apiErr.StatusCode) {
+		return 0, false
+	}
+	if d, ok := retryAfter(apiErr.Header); ok {
+		return d, true
+	}
+	return p.backoff(attempt), true
+}
+
+func (p *RetryPolicy) retryable(statusCode int) bool {
+	for _, c := range p.RetryableStatusCodes {
+		if c == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the exponential delay before retrying after attempt.
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	limit := p.MaxBackoff
+	if limit <= 0 {
+		limit = math.MaxInt64
+	}
+	// Comparing before shifting keeps large attempts from overflowing into short or negative delays.
+	d := limit
+	if shift := uint(attempt - 1); attempt >= 1 && shift < 63 && p.Backoff <= limit>>shift {
+		d = p.Backoff << shift
+	}
+	if p.Jitter > 0 {
+		if jittered := float64(d) * (1 + p.Jitter*(2*rand.Float64()-1)); jittered < math.MaxInt64 {
+			d = time.Duration(jittered)
+		}
+	}
+	return d
+}
+
+// retryAfter parses the Retry-After header, which is either a number of seconds or an HTTP date.
+func retryAfter(h http.Header) (time.Duration, bool) {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(v); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// sleep waits for d, returning early with the context's error if ctx is done first.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
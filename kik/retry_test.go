@@ -0,0 +1,162 @@
+package kik_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/r-kells/go-kik/kik"
+	"github.com/r-kells/go-kik/kiktest"
+)
+
+func testRetryPolicy() *kik.RetryPolicy {
+	policy := kik.DefaultRetryPolicy()
+	policy.Backoff = time.Millisecond
+	return policy
+}
+
+func TestRetryPolicy_RetriesServerErrors(t *testing.T) {
+	client, mux, teardown := kiktest.TestClient(t)
+	defer teardown()
+	client.RetryPolicy = testRetryPolicy()
+
+	attempts := 0
+	mux.HandleFunc(kik.GetUserUrl, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"firstName": "Ryan"}`)
+	})
+
+	user, err := client.GetUser(context.Background(), username)
+
+	if err != nil {
+		t.Errorf("GetUser(%s) returned an error = %+v; expected no error", username, err)
+	}
+	if attempts != 3 || user.FirstName != "Ryan" {
+		t.Errorf("GetUser(%s) = %v after %d attempts; want Ryan after 3", username, user, attempts)
+	}
+}
+
+func TestRetryPolicy_GivesUp(t *testing.T) {
+	client, mux, teardown := kiktest.TestClient(t)
+	defer teardown()
+	client.RetryPolicy = testRetryPolicy()
+
+	attempts := 0
+	mux.HandleFunc(kik.SendMessageUrl, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	err := client.SendMessage(context.Background(), []kik.Message{
+		kik.TextMessage{SendMessage: kik.SendMessage{To: username, Type: "text"}, Body: "Hi"},
+	})
+
+	var apiErr *kik.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway {
+		t.Errorf("Expected the last 502 *kik.APIError, got %v", err)
+	}
+	if attempts != client.RetryPolicy.MaxAttempts {
+		t.Errorf("made %d attempts; want %d", attempts, client.RetryPolicy.MaxAttempts)
+	}
+}
+
+func TestRetryPolicy_DoesNotRetryClientErrors(t *testing.T) {
+	client, mux, teardown := kiktest.TestClient(t)
+	defer teardown()
+	client.RetryPolicy = testRetryPolicy()
+
+	attempts := 0
+	mux.HandleFunc(kik.GetUserUrl, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadRequest)
+	})
+
+	client.GetUser(context.Background(), username)
+
+	if attempts != 1 {
+		t.Errorf("made %d attempts; want 1", attempts)
+	}
+}
+
+func TestRetryPolicy_HonorsRetryAfter(t *testing.T) {
+	client, mux, teardown := kiktest.TestClient(t)
+	defer teardown()
+	client.RetryPolicy = testRetryPolicy()
+
+	var attemptTimes []time.Time
+	mux.HandleFunc(kik.GetUserUrl, func(w http.ResponseWriter, r *http.Request) {
+		attemptTimes = append(attemptTimes, time.Now())
+		if len(attemptTimes) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	})
+
+	_, err := client.GetUser(context.Background(), username)
+
+	if err != nil {
+		t.Fatalf("GetUser(%s) returned an error = %+v; expected no error", username, err)
+	}
+	if waited := attemptTimes[1].Sub(attemptTimes[0]); waited < time.Second {
+		t.Errorf("retried after %v; want at least the 1s Retry-After", waited)
+	}
+}
+
+func TestRetryPolicy_StopsWhenContextIsDone(t *testing.T) {
+	client, mux, teardown := kiktest.TestClient(t)
+	defer teardown()
+	client.RetryPolicy = testRetryPolicy()
+	client.RetryPolicy.Backoff = time.Hour
+
+	mux.HandleFunc(kik.GetUserUrl, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := client.GetUser(ctx, username)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestRetryPolicy_NetworkErrorsOnSend(t *testing.T) {
+	client, mux, teardown := kiktest.TestClient(t)
+	defer teardown()
+	client.RetryPolicy = testRetryPolicy()
+
+	var attempts int32
+	mux.HandleFunc(kik.SendMessageUrl, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			// The connection drops before the response, Kik may have received the messages.
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+		}
+	})
+	send := func() error {
+		return client.SendMessage(context.Background(), []kik.Message{
+			kik.TextMessage{SendMessage: kik.SendMessage{Id: "m1", To: username, Type: "text"}, Body: "Hi"},
+		})
+	}
+
+	if err := send(); err == nil || atomic.LoadInt32(&attempts) != 1 {
+		t.Errorf("SendMessage() = %v after %d attempts; want the network error without a retry", err, atomic.LoadInt32(&attempts))
+	}
+
+	atomic.StoreInt32(&attempts, 0)
+	client.Dedupe = kik.NewMemoryDedupeStore()
+	if err := send(); err != nil || atomic.LoadInt32(&attempts) != 2 {
+		t.Errorf("SendMessage() with a Dedupe store = %v after %d attempts; want it sent after a retry", err, atomic.LoadInt32(&attempts))
+	}
+}
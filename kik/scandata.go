@@ -0,0 +1,37 @@
+package kik
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// MaxScanDataSize is the largest data, in bytes, CreateCode embeds in a Kik code, larger data is rejected without calling the API.
+const MaxScanDataSize = 2048
+
+// NewScanData returns the ScanData of a deep link, with payload embedded as JSON, e.g. a struct naming a campaign.
+// The bot gets the payload back with ScanDataReceive.DecodeData when a user scans the code.
+// Payloads larger than MaxScanDataSize once encoded return an InvalidScanDataError.
+func NewScanData(payload interface{}) (*ScanData, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	s := &ScanData{Data: string(data)}
+	return s, s.Validate()
+}
+
+// Validate returns an InvalidScanDataError if the data is larger than MaxScanDataSize.
+func (s *ScanData) Validate() error {
+	if len(s.Data) > MaxScanDataSize {
+		return fmt.Errorf("%w: %d bytes, larger than %d", InvalidScanDataError, len(s.Data), MaxScanDataSize)
+	}
+	return nil
+}
+
+// DecodeData unmarshals the data of the scanned code into v, the payload of NewScanData.
+func (m *ScanDataReceive) DecodeData(v interface{}) error {
+	if err := json.Unmarshal([]byte(m.Data), v); err != nil {
+		return fmt.Errorf("%w: %v", InvalidScanDataError, err)
+	}
+	return nil
+}
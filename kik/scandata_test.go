@@ -0,0 +1,63 @@
+package kik_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/r-kells/go-kik/kik"
+	"github.com/r-kells/go-kik/kiktest"
+)
+
+type campaign struct {
+	Name string `json:"name"`
+	Step int    `json:"step"`
+}
+
+func TestScanData_RoundTrip(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+
+	scanData, err := kik.NewScanData(campaign{Name: "spring", Step: 2})
+	if err != nil {
+		t.Fatalf("NewScanData() returned an error = %+v; expected no error", err)
+	}
+	code, err := server.Client.CreateCode(context.Background(), scanData)
+	if err != nil {
+		t.Fatalf("CreateCode() returned an error = %+v; expected no error", err)
+	}
+	data, _ := server.CodeData(code.Id)
+
+	var got campaign
+	scanned := &kik.ScanDataReceive{ReceiveMessage: kik.ReceiveMessage{Type: "scan-data", From: "laura"}, Data: data}
+	if err := scanned.DecodeData(&got); err != nil {
+		t.Fatalf("DecodeData() returned an error = %+v; expected no error", err)
+	}
+	if got.Name != "spring" || got.Step != 2 {
+		t.Errorf("DecodeData() = %+v; want the payload of the code", got)
+	}
+}
+
+func TestScanData_TooLarge(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+
+	if _, err := kik.NewScanData(strings.Repeat("a", kik.MaxScanDataSize)); !errors.Is(err, kik.InvalidScanDataError) {
+		t.Errorf("NewScanData() = %v; want InvalidScanDataError", err)
+	}
+
+	_, err := server.Client.CreateCode(context.Background(), &kik.ScanData{Data: strings.Repeat("a", kik.MaxScanDataSize+1)})
+	if !errors.Is(err, kik.InvalidScanDataError) {
+		t.Errorf("CreateCode() = %v; want InvalidScanDataError", err)
+	}
+}
+
+func TestScanDataReceive_DecodeDataInvalid(t *testing.T) {
+	scanned := &kik.ScanDataReceive{Data: "not json"}
+
+	var got campaign
+	if err := scanned.DecodeData(&got); !errors.Is(err, kik.InvalidScanDataError) {
+		t.Errorf("DecodeData() = %v; want InvalidScanDataError", err)
+	}
+}
@@ -0,0 +1,165 @@
+package kik
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+const (
+	// DefaultHealthPath is the path a Server answers health checks on.
+	DefaultHealthPath = "/healthz"
+	// DefaultShutdownTimeout is how long a Server waits for requests in flight when it shuts down.
+	DefaultShutdownTimeout = 10 * time.Second
+	// DefaultReadHeaderTimeout is how long a Server waits for the headers of a request,
+	// so clients sending them slowly do not keep connections open.
+	DefaultReadHeaderTimeout = 5 * time.Second
+	// DefaultReadTimeout is how long a Server waits for a whole request, up to MaxWebhookBodySize.
+	DefaultReadTimeout = 30 * time.Second
+	// DefaultIdleTimeout is how long a Server keeps idle connections open for the next request.
+	DefaultIdleTimeout = 2 * time.Minute
+)
+
+// Server runs a webhook, e.g. a Bot or WebhookHandler, with the plumbing a deployment needs:
+// a health check endpoint, optional TLS, graceful shutdown and recovery from panics in the webhook.
+type Server struct {
+	Addr    string       // The address to listen on, e.g. ":8080".
+	Webhook http.Handler // Serves every path except HealthPath.
+
+	HealthPath string       // Defaults to DefaultHealthPath.
+	Healthy    func() error // Reports, if set, why health checks should fail with a 503.
+
+	// TLS is used if TLSCertFile and TLSKeyFile, or TLSConfig with certificates, are set.
+	TLSCertFile string
+	TLSKeyFile  string
+	TLSConfig   *tls.Config
+
+	ShutdownTimeout time.Duration // Defaults to DefaultShutdownTimeout.
+
+	// Timeouts of the http.Server, see its fields of the same name. Zero uses the default,
+	// a negative timeout disables it. There is no WriteTimeout by default, as handlers may wait for the Kik API.
+	ReadHeaderTimeout time.Duration // Defaults to DefaultReadHeaderTimeout.
+	ReadTimeout       time.Duration // Defaults to DefaultReadTimeout.
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration // Defaults to DefaultIdleTimeout.
+
+	// OnError is called, if set, with the panics recovered from the webhook.
+	OnError func(r *http.Request, err error)
+}
+
+// NewServer returns a Server running webhook on addr.
+func NewServer(addr string, webhook http.Handler) *Server {
+	return &Server{Addr: addr, Webhook: webhook}
+}
+
+// Run listens on Addr and serves until ctx is done, then shuts down gracefully.
+func (s *Server) Run(ctx context.Context) error {
+	l, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ctx, l)
+}
+
+// Serve serves on l until ctx is done, then stops accepting connections and waits up to ShutdownTimeout
+// for requests in flight. It returns nil after a graceful shutdown.
+func (s *Server) Serve(ctx context.Context, l net.Listener) error {
+	srv := &http.Server{
+		Handler:           s.Handler(),
+		TLSConfig:         s.TLSConfig,
+		ReadHeaderTimeout: serverTimeout(s.ReadHeaderTimeout, DefaultReadHeaderTimeout),
+		ReadTimeout:       serverTimeout(s.ReadTimeout, DefaultReadTimeout),
+		WriteTimeout:      serverTimeout(s.WriteTimeout, 0),
+		IdleTimeout:       serverTimeout(s.IdleTimeout, DefaultIdleTimeout),
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		if s.TLSCertFile != "" || s.hasCertificates() {
+			errs <- srv.ServeTLS(l, s.TLSCertFile, s.TLSKeyFile)
+		} else {
+			errs <- srv.Serve(l)
+		}
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	timeout := s.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = DefaultShutdownTimeout
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errs; err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// hasCertificates reports whether the TLSConfig can serve TLS without TLSCertFile and TLSKeyFile.
+func (s *Server) hasCertificates() bool {
+	c := s.TLSConfig
+	return c != nil && (len(c.Certificates) > 0 || c.GetCertificate != nil || c.GetConfigForClient != nil)
+}
+
+// serverTimeout returns d, or def if d is zero. Negative timeouts are disabled, like zero timeouts of an http.Server.
+func serverTimeout(d, def time.Duration) time.Duration {
+	switch {
+	case d < 0:
+		return 0
+	case d == 0:
+		return def
+	}
+	return d
+}
+
+// Handler returns the http.Handler the Server serves, the health check and the webhook with panic recovery.
+func (s *Server) Handler() http.Handler {
+	healthPath := s.HealthPath
+	if healthPath == "" {
+		healthPath = DefaultHealthPath
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(healthPath, s.health)
+	mux.Handle("/", s.recoverPanics(s.Webhook))
+	return mux
+}
+
+func (s *Server) health(w http.ResponseWriter, r *http.Request) {
+	if s.Healthy != nil {
+		if err := s.Healthy(); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+	}
+	fmt.Fprintln(w, "ok")
+}
+
+// recoverPanics responds with a 500, so Kik delivers the payload again, if next panics.
+func (s *Server) recoverPanics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if v := recover(); v != nil {
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+				if s.OnError != nil {
+					s.OnError(r, fmt.Errorf("panic serving webhook: %v", v))
+				}
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,145 @@
+package kik_test
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/r-kells/go-kik/kik"
+)
+
+func TestServer_Health(t *testing.T) {
+	server := kik.NewServer(":0", http.NotFoundHandler())
+
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, httptest.NewRequest("GET", kik.DefaultHealthPath, nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("GET %s status = %d; want %d", kik.DefaultHealthPath, rec.Code, http.StatusOK)
+	}
+
+	server.Healthy = func() error { return errors.New("store unreachable") }
+	rec = httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, httptest.NewRequest("GET", kik.DefaultHealthPath, nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("GET %s status = %d when unhealthy; want %d", kik.DefaultHealthPath, rec.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestServer_RecoversPanics(t *testing.T) {
+	server := kik.NewServer(":0", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("handler bug")
+	}))
+	var gotErr error
+	server.OnError = func(r *http.Request, err error) { gotErr = err }
+
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, httptest.NewRequest("POST", "/incoming", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("ServeHTTP() status = %d; want %d", rec.Code, http.StatusInternalServerError)
+	}
+	if gotErr == nil {
+		t.Errorf("OnError was not called for the panic")
+	}
+}
+
+func TestServer_GracefulShutdown(t *testing.T) {
+	started := make(chan struct{})
+	server := kik.NewServer("", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		fmt.Fprint(w, "handled")
+	}))
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- server.Serve(ctx, l) }()
+
+	type result struct {
+		body string
+		err  error
+	}
+	responses := make(chan result, 1)
+	go func() {
+		resp, err := http.Post("http://"+l.Addr().String()+"/incoming", "application/json", nil)
+		if err != nil {
+			responses <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		b, err := ioutil.ReadAll(resp.Body)
+		responses <- result{string(b), err}
+	}()
+
+	<-started
+	cancel()
+
+	if r := <-responses; r.err != nil || r.body != "handled" {
+		t.Errorf("request in flight = %q, %v; want it to complete during shutdown", r.body, r.err)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("Serve() returned an error = %+v; expected no error after a graceful shutdown", err)
+	}
+}
+
+// serve runs server on a local port until the returned stop is called.
+func serve(t *testing.T, server *kik.Server) (addr string, stop func()) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- server.Serve(ctx, l) }()
+	return l.Addr().String(), func() {
+		cancel()
+		<-done
+	}
+}
+
+func TestServer_ReadHeaderTimeout(t *testing.T) {
+	server := kik.NewServer("", http.NotFoundHandler())
+	server.ReadHeaderTimeout = 20 * time.Millisecond
+	addr, stop := serve(t, server)
+	defer stop()
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	fmt.Fprint(conn, "POST /incoming HTTP/1.1\r\nHost: bot\r\n") // The headers never end.
+
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	if _, err := ioutil.ReadAll(conn); err != nil {
+		t.Errorf("slow request was not closed by the server: %v", err)
+	}
+}
+
+func TestServer_TLSConfigWithoutCertificates(t *testing.T) {
+	// E.g. a TLSConfig with only MinVersion set, without TLSCertFile and TLSKeyFile, serves plain HTTP.
+	server := kik.NewServer("", http.NotFoundHandler())
+	server.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	addr, stop := serve(t, server)
+	defer stop()
+
+	resp, err := http.Get("http://" + addr + kik.DefaultHealthPath)
+	if err != nil {
+		t.Fatalf("GET %s returned an error = %+v; expected no error", kik.DefaultHealthPath, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("GET %s status = %d; want %d", kik.DefaultHealthPath, resp.StatusCode, http.StatusOK)
+	}
+}
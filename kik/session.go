@@ -0,0 +1,182 @@
+package kik
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// SessionStore persists encoded sessions, e.g. in memory or in Redis with GET, SET EX and DEL.
+type SessionStore interface {
+	// Load returns the data saved for key, the bool is false if there is none or it expired.
+	Load(ctx context.Context, key string) ([]byte, bool, error)
+	// Save stores data for key, replacing any previous data, until ttl has elapsed.
+	Save(ctx context.Context, key string, data []byte, ttl time.Duration) error
+	// Delete removes key from the store.
+	Delete(ctx context.Context, key string) error
+}
+
+// MemorySessionStore is an in-memory SessionStore that is safe for concurrent use.
+type MemorySessionStore struct {
+	mu        sync.Mutex
+	sessions  map[string]memorySession
+	lastSweep time.Time
+}
+
+// sessionSweepInterval is how often expired sessions are dropped from a MemorySessionStore,
+// Load drops the expired session it finds in between.
+const sessionSweepInterval = time.Minute
+
+type memorySession struct {
+	data   []byte
+	expiry time.Time
+}
+
+// NewMemorySessionStore returns an empty MemorySessionStore.
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{sessions: make(map[string]memorySession)}
+}
+
+func (s *MemorySessionStore) Load(ctx context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if !time.Now().Before(session.expiry) {
+		delete(s.sessions, key)
+		return nil, false, nil
+	}
+	return session.data, true, nil
+}
+
+func (s *MemorySessionStore) Save(ctx context.Context, key string, data []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Drop expired sessions now and then so the map doesn't grow forever.
+	now := time.Now()
+	if now.Sub(s.lastSweep) >= sessionSweepInterval {
+		for k, session := range s.sessions {
+			if !now.Before(session.expiry) {
+				delete(s.sessions, k)
+			}
+		}
+		s.lastSweep = now
+	}
+	s.sessions[key] = memorySession{data: append([]byte(nil), data...), expiry: now.Add(ttl)}
+	return nil
+}
+
+func (s *MemorySessionStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, key)
+	return nil
+}
+
+// Session is the state of a conversation with one user in one chat, e.g. the step of a multi-step dialog.
+// A Session is not safe for concurrent use.
+type Session struct {
+	User   string            `json:"user"`
+	ChatId string            `json:"chatId"`
+	Values map[string]string `json:"values,omitempty"`
+}
+
+// Get returns the value stored for key, or an empty string.
+func (s *Session) Get(key string) string {
+	return s.Values[key]
+}
+
+// Set stores value for key.
+func (s *Session) Set(key, value string) {
+	if s.Values == nil {
+		s.Values = make(map[string]string)
+	}
+	s.Values[key] = value
+}
+
+// Delete removes key.
+func (s *Session) Delete(key string) {
+	delete(s.Values, key)
+}
+
+// Clear removes every value, the session is deleted from the store when it is saved.
+func (s *Session) Clear() {
+	s.Values = nil
+}
+
+// SessionKey returns the key a conversation with user in chatId is stored under.
+func SessionKey(user, chatId string) string {
+	return "session:" + user + ":" + chatId
+}
+
+// DefaultSessionTTL is how long sessions are kept after their last change by Sessions without a TTL set.
+const DefaultSessionTTL = 24 * time.Hour
+
+// Sessions loads and saves the Session of the conversation each incoming message belongs to.
+type Sessions struct {
+	Store SessionStore  // Defaults to a MemorySessionStore.
+	TTL   time.Duration // Defaults to DefaultSessionTTL.
+
+	once sync.Once
+}
+
+// NewSessions returns Sessions saved in store.
+func NewSessions(store SessionStore) *Sessions {
+	return &Sessions{Store: store}
+}
+
+func (s *Sessions) init() {
+	s.once.Do(func() {
+		if s.Store == nil {
+			s.Store = NewMemorySessionStore()
+		}
+		if s.TTL <= 0 {
+			s.TTL = DefaultSessionTTL
+		}
+	})
+}
+
+// Load returns the session of the conversation m was sent in, a new empty session if none is stored.
+func (s *Sessions) Load(ctx context.Context, m Receive) (*Session, error) {
+	s.init()
+	h := m.header()
+	session := &Session{User: h.From, ChatId: h.ChatId}
+
+	data, ok, err := s.Store.Load(ctx, SessionKey(h.From, h.ChatId))
+	if err != nil || !ok {
+		return session, err
+	}
+	if err := json.Unmarshal(data, session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// Save stores session, or deletes it from the store if it has no values.
+func (s *Sessions) Save(ctx context.Context, session *Session) error {
+	s.init()
+	key := SessionKey(session.User, session.ChatId)
+	if len(session.Values) == 0 {
+		return s.Store.Delete(ctx, key)
+	}
+
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	return s.Store.Save(ctx, key, data, s.TTL)
+}
+
+type sessionContextKey struct{}
+
+// SessionFromContext returns the session a Bot with Sessions loaded for the message being handled, or nil.
+// Changes to it are saved once the handler returns without an error.
+func SessionFromContext(ctx context.Context) *Session {
+	session, _ := ctx.Value(sessionContextKey{}).(*Session)
+	return session
+}
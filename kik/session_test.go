@@ -0,0 +1,103 @@
+package kik_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/r-kells/go-kik/kik"
+	"github.com/r-kells/go-kik/kiktest"
+)
+
+func TestSessions_PerConversation(t *testing.T) {
+	ctx := context.Background()
+	sessions := kik.NewSessions(kik.NewMemorySessionStore())
+
+	session, err := sessions.Load(ctx, textFrom(username, "c1", "Hi"))
+	if err != nil {
+		t.Fatalf("Load() returned an error = %+v; expected no error", err)
+	}
+	session.Set("step", "name")
+	if err := sessions.Save(ctx, session); err != nil {
+		t.Fatalf("Save() returned an error = %+v; expected no error", err)
+	}
+
+	tests := []struct {
+		from, chatId string
+		want         string
+	}{
+		{username, "c1", "name"},
+		{username, "c2", ""},
+		{"someoneelse", "c1", ""},
+	}
+	for _, test := range tests {
+		got, err := sessions.Load(ctx, textFrom(test.from, test.chatId, "Hi"))
+		if err != nil || got.Get("step") != test.want {
+			t.Errorf("Load(%s, %s) step = %q, %v; want %q", test.from, test.chatId, got.Get("step"), err, test.want)
+		}
+	}
+}
+
+func TestSessions_ClearDeletes(t *testing.T) {
+	ctx := context.Background()
+	store := kik.NewMemorySessionStore()
+	sessions := kik.NewSessions(store)
+
+	session := &kik.Session{User: username, ChatId: "c1"}
+	session.Set("step", "name")
+	sessions.Save(ctx, session)
+
+	session.Clear()
+	if err := sessions.Save(ctx, session); err != nil {
+		t.Fatalf("Save() returned an error = %+v; expected no error", err)
+	}
+	if _, ok, _ := store.Load(ctx, kik.SessionKey(username, "c1")); ok {
+		t.Errorf("store still has the session after saving it cleared")
+	}
+}
+
+func TestMemorySessionStore_Expires(t *testing.T) {
+	ctx := context.Background()
+	store := kik.NewMemorySessionStore()
+
+	store.Save(ctx, "key", []byte("data"), 20*time.Millisecond)
+	if data, ok, _ := store.Load(ctx, "key"); !ok || string(data) != "data" {
+		t.Errorf("Load(key) = %s, %v; want data", data, ok)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if _, ok, _ := store.Load(ctx, "key"); ok {
+		t.Errorf("Load(key) found the data after its ttl")
+	}
+}
+
+func TestBot_Sessions(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+
+	bot := kik.NewBot(server.Client)
+	bot.Sessions = kik.NewSessions(nil)
+	bot.HandleText("", func(ctx context.Context, m *kik.TextMessageReceive) ([]kik.Message, error) {
+		session := kik.SessionFromContext(ctx)
+		if session.Get("step") == "" {
+			session.Set("step", "name")
+			return reply("What's your name?")
+		}
+		session.Clear()
+		return reply("Hi " + m.Body)
+	})
+
+	for _, body := range []string{"Hi", "Laura", "Hi"} {
+		if err := bot.HandleMessage(context.Background(), textFrom(username, "c1", body)); err != nil {
+			t.Fatalf("HandleMessage(%s) returned an error = %+v; expected no error", body, err)
+		}
+	}
+
+	var got []string
+	for _, m := range server.Messages() {
+		got = append(got, m.Body)
+	}
+	if len(got) != 3 || got[0] != "What's your name?" || got[1] != "Hi Laura" || got[2] != "What's your name?" {
+		t.Errorf("replies = %v; want the dialog to restart after it finished", got)
+	}
+}
@@ -0,0 +1,54 @@
+package kik
+
+import "fmt"
+
+/*
+Stickers
+
+Kik does not expose an endpoint listing its sticker packs, and bots can only receive stickers, not send them.
+Bots that rely on specific packs (e.g. sticker games) can validate incoming stickers with a StickerPackAllowlist,
+and can show a sticker's image with AsPicture.
+
+Docs for Stickers: https://dev.kik.com/#/docs/messaging#sticker
+*/
+
+// StickerMessageReceive is the data structure returned from the Kik API when a user sends the bot a sticker.
+type StickerMessageReceive struct {
+	ReceiveMessage
+	StickerPackId string `json:"stickerPackId"` // The ID of the sticker pack the sticker belongs to.
+	StickerUrl    string `json:"stickerUrl"`    // The URL of the sticker image.
+}
+
+// AsPicture returns a picture message showing the sticker's image, sent back to the same user and chat.
+// Bots can not send stickers, this is the closest outgoing message.
+func (m *StickerMessageReceive) AsPicture() PictureMessage {
+	s := echoTo(m.ReceiveMessage)
+	s.Type = "picture"
+	return PictureMessage{SendMessage: s, PicUrl: m.StickerUrl}
+}
+
+// StickerPackAllowlist is a set of sticker pack IDs a bot knows how to handle.
+type StickerPackAllowlist map[string]struct{}
+
+// NewStickerPackAllowlist returns an allowlist containing the given sticker pack IDs.
+func NewStickerPackAllowlist(packIds ...string) StickerPackAllowlist {
+	a := make(StickerPackAllowlist, len(packIds))
+	for _, id := range packIds {
+		a[id] = struct{}{}
+	}
+	return a
+}
+
+// Allowed reports whether packId is in the allowlist.
+func (a StickerPackAllowlist) Allowed(packId string) bool {
+	_, ok := a[packId]
+	return ok
+}
+
+// Validate returns an UnknownStickerPackError if the sticker does not belong to an allowed pack.
+func (a StickerPackAllowlist) Validate(s *StickerMessageReceive) error {
+	if !a.Allowed(s.StickerPackId) {
+		return fmt.Errorf("%w: %q", UnknownStickerPackError, s.StickerPackId)
+	}
+	return nil
+}
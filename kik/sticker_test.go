@@ -0,0 +1,81 @@
+package kik_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/r-kells/go-kik/kik"
+)
+
+const stickerPayload = `{"messages": [{
+	"chatId": "0ee6d46753bfa6ac2f089149959363f3f59ae62b10cba89cc426490ce38ea92d",
+	"id": "0115efde-e54b-43d5-873a-5fef7adc69fd",
+	"type": "sticker",
+	"from": "laura",
+	"participants": ["laura"],
+	"stickerPackId": "memes",
+	"stickerUrl": "http://cards-sticker-dev.herokuapp.com/stickers/memes/okay.png",
+	"timestamp": 1439576628405,
+	"readReceiptRequested": true
+}]}`
+
+func TestStickerMessageReceive_Unmarshal(t *testing.T) {
+	var messages kik.ReceivedMessages
+	if err := json.Unmarshal([]byte(stickerPayload), &messages); err != nil {
+		t.Fatalf("Unmarshal returned an error = %+v; expected no error", err)
+	}
+
+	sticker, ok := messages[0].(*kik.StickerMessageReceive)
+	if !ok {
+		t.Fatalf("got %T; want *kik.StickerMessageReceive", messages[0])
+	}
+	if sticker.StickerPackId != "memes" {
+		t.Errorf("StickerPackId = %s; want memes", sticker.StickerPackId)
+	}
+}
+
+func TestStickerPackAllowlist_Validate(t *testing.T) {
+	allowlist := kik.NewStickerPackAllowlist("memes", "cats")
+
+	tests := []struct {
+		packId  string
+		wantErr bool
+	}{
+		{"memes", false},
+		{"cats", false},
+		{"dogs", true},
+		{"", true},
+	}
+	for _, tt := range tests {
+		err := allowlist.Validate(&kik.StickerMessageReceive{StickerPackId: tt.packId})
+		if tt.wantErr && !errors.Is(err, kik.UnknownStickerPackError) {
+			t.Errorf("Validate(%q) = %v; want UnknownStickerPackError", tt.packId, err)
+		}
+		if !tt.wantErr && err != nil {
+			t.Errorf("Validate(%q) = %v; want no error", tt.packId, err)
+		}
+	}
+}
+
+func TestStickerMessageReceive_AsPicture(t *testing.T) {
+	sticker := &kik.StickerMessageReceive{
+		ReceiveMessage: kik.ReceiveMessage{Type: "sticker", From: "laura", ChatId: "c1"},
+		StickerPackId:  "memes",
+		StickerUrl:     "http://cards-sticker-dev.herokuapp.com/stickers/memes/okay.png",
+	}
+
+	got := sticker.AsPicture()
+
+	want := kik.PictureMessage{
+		SendMessage: kik.SendMessage{To: "laura", Type: "picture", ChatId: "c1"},
+		PicUrl:      sticker.StickerUrl,
+	}
+	if !cmp.Equal(got, want) {
+		t.Errorf("AsPicture() = %v; want %v", got, want)
+	}
+	if err := kik.ValidateMessage(got); err != nil {
+		t.Errorf("ValidateMessage(AsPicture()) returned an error = %+v; expected no error", err)
+	}
+}
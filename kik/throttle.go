@@ -0,0 +1,114 @@
+package kik
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ThrottleMetrics is implemented by Metrics that also record throttled incoming messages, e.g. the kikmetrics Collector.
+type ThrottleMetrics interface {
+	// ObserveThrottle is called for every message a Throttle kept from its handler.
+	ObserveThrottle(messageType string)
+}
+
+// Throttle limits how many messages each user may have handled by a Bot, so a user flooding the bot
+// can not make its handlers flood the services behind them. Every user has a token bucket of Burst
+// messages, refilled at Rate messages per second. Receipts and typing indicators are never throttled.
+//
+//	throttle := kik.NewThrottle(1, 10)
+//	throttle.OnThrottled = func(ctx context.Context, m kik.Receive) ([]kik.Message, error) {
+//		return []kik.Message{kik.TextMessage{SendMessage: kik.SendMessage{Type: "text"}, Body: "Slow down!"}}, nil
+//	}
+//	bot.Use(throttle.Middleware)
+type Throttle struct {
+	Rate  float64 // Messages per second per user.
+	Burst int     // Messages a user may send at once.
+
+	// OnThrottled is called, if set, instead of the handler of a throttled message, and its replies are sent.
+	// Throttled messages are ignored otherwise.
+	OnThrottled BotHandler
+
+	// Metrics, if set, records every throttled message.
+	Metrics ThrottleMetrics
+
+	mu        sync.Mutex
+	users     map[string]*userBucket
+	lastSweep time.Time
+}
+
+type userBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// throttleSweepInterval is how often buckets that refilled completely are dropped, they are created again when needed.
+const throttleSweepInterval = time.Minute
+
+// NewThrottle returns a Throttle allowing each user perSecond messages on average, and up to burst at once.
+func NewThrottle(perSecond float64, burst int) *Throttle {
+	return &Throttle{Rate: perSecond, Burst: burst}
+}
+
+// Allow takes a token from the bucket of username, and reports whether it had one left.
+func (t *Throttle) Allow(username string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	if t.users == nil {
+		t.users = make(map[string]*userBucket)
+		t.lastSweep = now
+	}
+	if now.Sub(t.lastSweep) >= throttleSweepInterval {
+		for u, b := range t.users {
+			if t.refill(b, now) >= float64(t.Burst) {
+				delete(t.users, u)
+			}
+		}
+		t.lastSweep = now
+	}
+
+	b, ok := t.users[username]
+	if !ok {
+		b = &userBucket{tokens: float64(t.Burst), last: now}
+		t.users[username] = b
+	}
+	if t.refill(b, now) < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// refill adds the tokens b earned since it was last refilled and returns them, t.mu must be held.
+func (t *Throttle) refill(b *userBucket, now time.Time) float64 {
+	b.tokens += now.Sub(b.last).Seconds() * t.Rate
+	if b.tokens > float64(t.Burst) {
+		b.tokens = float64(t.Burst)
+	}
+	b.last = now
+	return b.tokens
+}
+
+// Middleware is the Middleware of the Throttle, see Bot.Use.
+func (t *Throttle) Middleware(next BotHandler) BotHandler {
+	return func(ctx context.Context, m Receive) ([]Message, error) {
+		h := m.header()
+		switch h.Type {
+		case "delivery-receipt", "read-receipt", "is-typing":
+			return next(ctx, m)
+		}
+		if t.Allow(h.From) {
+			return next(ctx, m)
+		}
+
+		if t.Metrics != nil {
+			t.Metrics.ObserveThrottle(h.Type)
+		}
+		if t.OnThrottled != nil {
+			return t.OnThrottled(ctx, m)
+		}
+		return nil, nil
+	}
+}
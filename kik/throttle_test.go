@@ -0,0 +1,62 @@
+package kik_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/r-kells/go-kik/kik"
+	"github.com/r-kells/go-kik/kikmetrics"
+	"github.com/r-kells/go-kik/kiktest"
+)
+
+func TestThrottle_Allow(t *testing.T) {
+	throttle := kik.NewThrottle(0.001, 2)
+
+	for i, want := range []bool{true, true, false} {
+		if got := throttle.Allow("spammer"); got != want {
+			t.Errorf("Allow(spammer) #%d = %v; want %v", i, got, want)
+		}
+	}
+	if !throttle.Allow("laura") {
+		t.Errorf("Allow(laura) = false; want every user to have their own bucket")
+	}
+}
+
+func TestThrottle_Middleware(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+
+	var handled int
+	bot := kik.NewBot(server.Client)
+	bot.HandleText("", func(ctx context.Context, m *kik.TextMessageReceive) ([]kik.Message, error) {
+		handled++
+		return nil, nil
+	})
+	collector := kikmetrics.NewCollector()
+	throttle := kik.NewThrottle(0.001, 1)
+	throttle.Metrics = collector
+	throttle.OnThrottled = func(ctx context.Context, m kik.Receive) ([]kik.Message, error) {
+		return reply("Slow down!")
+	}
+	bot.Use(throttle.Middleware)
+
+	for i := 0; i < 3; i++ {
+		if err := bot.HandleMessage(context.Background(), textFrom("spammer", "c1", "Hi")); err != nil {
+			t.Fatalf("HandleMessage() returned an error = %+v; expected no error", err)
+		}
+	}
+	receipt := &kik.ReadReceiptReceive{ReceiveMessage: kik.ReceiveMessage{Type: "read-receipt", From: "spammer", ChatId: "c1"}}
+	if err := bot.HandleMessage(context.Background(), receipt); err != nil {
+		t.Fatalf("HandleMessage() returned an error = %+v; expected no error", err)
+	}
+
+	if handled != 1 {
+		t.Errorf("handled %d messages; want only the first one", handled)
+	}
+	if sent := server.Messages(); len(sent) != 2 || sent[0].Body != "Slow down!" {
+		t.Errorf("HandleMessage() sent %+v; want the OnThrottled reply twice", sent)
+	}
+	if throttled := collector.Snapshot()[kik.EndpointWebhook].Throttled; throttled != 2 {
+		t.Errorf("Throttled = %d; want 2", throttled)
+	}
+}
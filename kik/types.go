@@ -1,18 +1,33 @@
 package kik
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // User is the response body of a User profile from the Kik bot API.
 type User struct {
 	FirstName              string
 	LastName               string
-	ProfilePicLastModified int64
+	ProfilePicLastModified Timestamp
 	ProfilePicUrl          string
 }
 
+// ProfilePicture is a profile picture downloaded by DownloadProfilePicture.
+type ProfilePicture struct {
+	Data         []byte
+	ContentType  string    // The Content-Type of the response, or sniffed from Data if it had none.
+	LastModified time.Time // The Last-Modified of the response, or the ProfilePicLastModified of the user.
+}
+
 /*
 Keyboard Types
 
@@ -26,10 +41,69 @@ type SuggestedResponseKeyboard struct {
 	To     string `json:"to,omitempty"`     // defaults to everyone in the conversation.
 	Hidden bool   `json:"hidden,omitempty"` // defaults to false.
 
-	// TODO: actually validate.
-	Responses []interface{} `json:"responses,omitempty"`
+	Responses []SuggestedResponse `json:"responses,omitempty"`
 }
 
+// UnmarshalJSON parses each response into its SuggestedResponse type.
+func (s *SuggestedResponseKeyboard) UnmarshalJSON(data []byte) error {
+	type keyboard SuggestedResponseKeyboard // Avoids recursing into UnmarshalJSON.
+	var raw struct {
+		keyboard
+		Responses []json.RawMessage `json:"responses,omitempty"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*s = SuggestedResponseKeyboard(raw.keyboard)
+	s.Responses = nil
+	for _, r := range raw.Responses {
+		var typed struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(r, &typed); err != nil {
+			return err
+		}
+
+		// Responses are values, like the ones sent.
+		var (
+			response SuggestedResponse
+			err      error
+		)
+		switch typed.Type {
+		case "text":
+			var text KeyboardTextResponse
+			err = json.Unmarshal(r, &text)
+			response = text
+		case "picture":
+			var picture KeyboardPictureResponse
+			err = json.Unmarshal(r, &picture)
+			response = picture
+		case "friend-picker":
+			var friendPicker KeyboardFriendPickerResponse
+			err = json.Unmarshal(r, &friendPicker)
+			response = friendPicker
+		default:
+			return fmt.Errorf("%w: suggested response %q", NotMessageTypeError, typed.Type)
+		}
+		if err != nil {
+			return err
+		}
+		s.Responses = append(s.Responses, response)
+	}
+	return nil
+}
+
+// SuggestedResponse is a dummy interface implemented by all types of responses in a SuggestedResponseKeyboard.
+type SuggestedResponse interface {
+	suggestedResponse()
+}
+
+// Implement the dummy interface
+func (r KeyboardTextResponse) suggestedResponse()         { return }
+func (r KeyboardPictureResponse) suggestedResponse()      { return }
+func (r KeyboardFriendPickerResponse) suggestedResponse() { return }
+
 // KeyboardTextResponse sets a text message in the keyboard tray.
 type KeyboardTextResponse struct {
 	Type string `json:"type"` // Type must be "text".
@@ -76,15 +150,20 @@ type Messages struct {
 // Message is a dummy interface so that all structs that embedd `Message` share a common interface.
 type Message interface {
 	message()
+	header() SendMessage
 }
 
 // Implement the dummy interface
 func (t SendMessage) message() { return }
 
+// header returns the fields shared by all outgoing message types.
+func (t SendMessage) header() SendMessage { return t }
+
 type SendMessage struct {
 	To        string                      `json:"to"`                  // The user or group that will receive the message
 	Type      string                      `json:"type"`                // The type of message. See Message Types for the values you can see in this field.
 	Delay     int                         `json:"delay"`               // An interval (in milliseconds) to wait before sending the message.
+	TypeTime  int                         `json:"typeTime,omitempty"`  // An interval (in milliseconds) to appear to be typing to the recipient before the message is sent. This occurs after delay.
 	Keyboards []SuggestedResponseKeyboard `json:"keyboards,omitempty"` // SuggestedResponseKeyboard is currently the only valid keyboard type
 	Id        string                      `json:"id,omitempty"`        // randomUUID() ID for this message.Use this to link messages to receipts.This will always be present for received messages.
 	ChatId    string                      `json:"chatId,omitempty"`    // The identifier for the conversation your bot is involved in. This field is recommended for all responses in order for messages to be routed correctly (for example, if you're messaging a user in a group)
@@ -95,78 +174,356 @@ type SendMessage struct {
 type ReceivedMessages []Receive
 
 // UnmarshalJSON knows how to parse Kik bot API responses into their correct types.
+// It fails if any message can not be parsed, see ParseIncomingMessages to keep the valid ones.
 func (v *ReceivedMessages) UnmarshalJSON(data []byte) error {
-	// This just splits up the JSON array into the raw JSON for each object
-	var raw map[string][]json.RawMessage
-	err := json.Unmarshal(data, &raw)
+	raw, err := splitMessages(JSONCodec{}, data)
 	if err != nil {
 		return err
 	}
 
-	messages := raw["messages"]
-	for _, r := range messages {
-		// unamrshal into a map to check the "type" field
-		var obj map[string]interface{}
-		err := json.Unmarshal(r, &obj)
+	for _, r := range raw {
+		actual, err := decodeReceive(JSONCodec{}, r, false)
 		if err != nil {
 			return err
 		}
+		*v = append(*v, actual)
+	}
+	return nil
+}
 
-		messageType := ""
-		if t, ok := obj["type"].(string); ok {
-			messageType = t
-		}
+// MessageParseError is returned by ParseIncomingMessages for a message that could not be parsed.
+type MessageParseError struct {
+	Index int             // The index of the message in the payload.
+	Raw   json.RawMessage // The JSON of the message.
+	Err   error
+}
 
-		// unmarshal again into the correct type
-		var actual Receive
-		switch messageType {
-		case "text":
-			actual = &TextMessageReceive{}
-		case "picture":
-			actual = &PictureMessageReceive{}
+func (e *MessageParseError) Error() string {
+	return fmt.Sprintf("message %d: %v", e.Index, e.Err)
+}
+
+func (e *MessageParseError) Unwrap() error { return e.Err }
+
+// ParseIncomingMessages parses a webhook payload, parsing each message independently.
+// The error is only set when the payload itself is malformed, in which case no messages are returned.
+// Otherwise a message that can not be parsed does not affect the others:
+// all valid messages are returned in order, along with a MessageParseError for each invalid one.
+func ParseIncomingMessages(body []byte) (ReceivedMessages, []*MessageParseError, error) {
+	return parseIncomingMessages(JSONCodec{}, body, nil)
+}
+
+// parseIncomingMessages is ParseIncomingMessages with codec, appending the messages to messages.
+func parseIncomingMessages(codec Codec, body []byte, messages ReceivedMessages) (ReceivedMessages, []*MessageParseError, error) {
+	raw, err := splitMessages(codec, body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var errs []*MessageParseError
+	for i, r := range raw {
+		actual, err := decodeReceive(codec, r, false)
+		if err != nil {
+			errs = append(errs, &MessageParseError{Index: i, Raw: r, Err: err})
+			continue
 		}
+		messages = append(messages, actual)
+	}
+	return messages, errs, nil
+}
 
-		err = json.Unmarshal(r, actual)
+// ParseMessages parses a webhook payload into the concrete type of each message, e.g. *TextMessageReceive.
+// It fails if any message can not be parsed, the error is a *MessageParseError for the first of them.
+func ParseMessages(body []byte) (ReceivedMessages, error) {
+	return parseMessages(body, false)
+}
+
+// ParseMessagesStrict is like ParseMessages, but also rejects messages with fields unknown to this package,
+// or without the type, id, from or chatId Kik sends with every message, as a MissingFieldError.
+// It is meant for tests and for catching changes to the Kik API early.
+func ParseMessagesStrict(body []byte) (ReceivedMessages, error) {
+	return parseMessages(body, true)
+}
+
+func parseMessages(body []byte, strict bool) (ReceivedMessages, error) {
+	raw, err := splitMessages(JSONCodec{}, body)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make(ReceivedMessages, 0, len(raw))
+	for i, r := range raw {
+		actual, err := decodeReceive(JSONCodec{}, r, strict)
 		if err != nil {
-			return err
+			return nil, &MessageParseError{Index: i, Raw: r, Err: err}
 		}
-		*v = append(*v, actual)
+		messages = append(messages, actual)
 	}
-	return nil
+	return messages, nil
+}
+
+// splitMessages splits up the JSON array into the raw JSON for each object.
+func splitMessages(codec Codec, data []byte) ([]json.RawMessage, error) {
+	var raw struct {
+		Messages []json.RawMessage `json:"messages"`
+	}
+	if err := unmarshal(codec, data, &raw); err != nil {
+		return nil, err
+	}
+	return raw.Messages, nil
+}
+
+// decodeReceive unmarshals a single message into the type matching its "type" field.
+// A strict decode rejects unknown fields and messages without the fields every message has.
+func decodeReceive(codec Codec, r json.RawMessage, strict bool) (Receive, error) {
+	var typed struct {
+		Type string `json:"type"`
+	}
+	if err := unmarshalValue(codec, r, &typed); err != nil {
+		return nil, err
+	}
+
+	var actual Receive
+	switch typed.Type {
+	case "text":
+		actual = &TextMessageReceive{}
+	case "picture":
+		actual = &PictureMessageReceive{}
+	case "link":
+		actual = &LinkMessageReceive{}
+	case "video":
+		actual = &VideoMessageReceive{}
+	case "sticker":
+		actual = &StickerMessageReceive{}
+	case "delivery-receipt":
+		actual = &DeliveryReceiptReceive{}
+	case "read-receipt":
+		actual = &ReadReceiptReceive{}
+	case "is-typing":
+		actual = &IsTypingReceive{}
+	case "start-chatting":
+		actual = &StartChattingReceive{}
+	case "scan-data":
+		actual = &ScanDataReceive{}
+	case "friend-picker":
+		actual = &FriendPickerReceive{}
+	default:
+		return nil, fmt.Errorf("%w: %q", NotMessageTypeError, typed.Type)
+	}
+
+	if !strict {
+		if err := unmarshalValue(codec, r, actual); err != nil {
+			return nil, err
+		}
+		return actual, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(r))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(actual); err != nil {
+		return nil, err
+	}
+	h := actual.header()
+	switch {
+	case h.Id == "":
+		return nil, fmt.Errorf("%w: %q", MissingFieldError, "id")
+	case h.From == "":
+		return nil, fmt.Errorf("%w: %q", MissingFieldError, "from")
+	case h.ChatId == "":
+		return nil, fmt.Errorf("%w: %q", MissingFieldError, "chatId")
+	}
+	return actual, nil
 }
 
 // Receive is a dummy interface so that all structs that embedd `Receive` share a common interface.
 type Receive interface {
 	receive()
+	header() ReceiveMessage
 }
 
 // Implements dummy interface.
 func (t ReceiveMessage) receive() { return }
 
+// header returns the fields shared by all incoming message types.
+func (t ReceiveMessage) header() ReceiveMessage { return t }
+
 type ReceiveMessage struct {
-	ChatId               string   `json:"chatId"`       // The identifier for the conversation your bot is involved in. This field is recommended for all responses in order for messages to be routed correctly (for example, if you're messaging a user in a group)
-	Id                   string   `json:"id"`           // randomUUID() ID for this message.Use this to link messages to receipts.This will always be present for received messages.
-	From                 string   `json:"from"`         // The user who sent the message
-	Type                 string   `json:"type"`         // The type of message. See Message Types for the values you can see in this field.
-	Participants         []string `json:"participants"` // The type of conversation the message originated from.
-	Timestamp            int      `json:"timestamp"`    // The time the message was sent from the Kik client
-	ReadReceiptRequested bool     `json:"readReceiptRequested"`
+	ChatId               string    `json:"chatId"`       // The identifier for the conversation your bot is involved in. This field is recommended for all responses in order for messages to be routed correctly (for example, if you're messaging a user in a group)
+	Id                   string    `json:"id"`           // randomUUID() ID for this message.Use this to link messages to receipts.This will always be present for received messages.
+	From                 string    `json:"from"`         // The user who sent the message
+	Type                 string    `json:"type"`         // The type of message. See Message Types for the values you can see in this field.
+	Participants         []string  `json:"participants"` // The users in the conversation the message originated from.
+	Timestamp            Timestamp `json:"timestamp"`    // The time the message was sent from the Kik client
+	ReadReceiptRequested bool      `json:"readReceiptRequested"`
+
+	ChatType string   `json:"chatType,omitempty"` // The type of conversation the message originated from, ChatTypeDirect, ChatTypePrivate or ChatTypePublic.
+	Mention  string   `json:"mention,omitempty"`  // The username of the bot mentioned in the message.
+	Metadata Metadata `json:"metadata,omitempty"` // Metadata that was provided by your bot when sending the user a suggested response.
+}
+
+// Metadata is the metadata of the suggested response a user tapped, as the bot sent it.
+// Kik returns it as a string or a JSON object, objects are kept as their JSON text.
+type Metadata string
+
+// UnmarshalJSON accepts a string, or any other JSON value which is kept as its compact JSON text.
+func (m *Metadata) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	switch {
+	case len(data) > 0 && data[0] == '"':
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*m = Metadata(s)
+	case string(data) == "null":
+		*m = ""
+	default:
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, data); err != nil {
+			return err
+		}
+		*m = Metadata(compact.String())
+	}
+	return nil
+}
+
+// Decode unmarshals the metadata as JSON into v, e.g. a struct the bot sent as the metadata of a response,
+// either as an object or encoded as a JSON string.
+func (m Metadata) Decode(v interface{}) error {
+	return json.Unmarshal([]byte(m), v)
+}
 
-	ChatType string `json:"chatType,omitempty"` // The type of conversation the message originated from.
-	Mention  string `json:"mention,omitempty"`  // The username of the bot mentioned in the message.
-	Metadata string `json:"metadata,omitempty"` // Metadata that was provided by your bot when sending the user a suggested response.
+// Timestamp is a time the Kik API sends as milliseconds since the Unix epoch, the zero Timestamp is sent as 0.
+type Timestamp struct {
+	time.Time
 }
 
+// TimestampFromMillis returns the Timestamp of ms milliseconds since the Unix epoch, or the zero Timestamp for 0.
+func TimestampFromMillis(ms int64) Timestamp {
+	if ms == 0 {
+		return Timestamp{}
+	}
+	return Timestamp{time.Unix(ms/1000, ms%1000*int64(time.Millisecond))}
+}
+
+// Millis returns t as milliseconds since the Unix epoch, or 0 for the zero Timestamp.
+func (t Timestamp) Millis() int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+// Equal reports whether t and u are the same instant, see time.Time.Equal.
+func (t Timestamp) Equal(u Timestamp) bool {
+	return t.Time.Equal(u.Time)
+}
+
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatInt(t.Millis(), 10)), nil
+}
+
+// UnmarshalJSON accepts milliseconds since the Unix epoch, or null for the zero Timestamp.
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if string(data) == "null" {
+		*t = Timestamp{}
+		return nil
+	}
+	var ms json.Number
+	if err := json.Unmarshal(data, &ms); err != nil {
+		return fmt.Errorf("timestamp %s: %w", data, err)
+	}
+	n, err := ms.Int64()
+	if err != nil {
+		f, ferr := ms.Float64() // Some clients send fractional milliseconds.
+		if ferr != nil {
+			return fmt.Errorf("timestamp %s: %w", data, err)
+		}
+		n = int64(f)
+	}
+	*t = TimestampFromMillis(n)
+	return nil
+}
+
+// Age returns how long ago m was sent, or 0 if it has no Timestamp.
+// It relies on the clocks of the user's device and the bot, so it may be slightly off or negative.
+func (m ReceiveMessage) Age() time.Duration {
+	if m.Timestamp.IsZero() {
+		return 0
+	}
+	return time.Since(m.Timestamp.Time)
+}
+
+// FromKeyboard reports whether m was sent by tapping a suggested response, rather than typed by the user.
+// Kik only returns the metadata of a response, so responses need a Metadata to be recognized.
+func (m ReceiveMessage) FromKeyboard() bool {
+	return m.Metadata != ""
+}
+
+// The chat types of incoming messages.
+const (
+	ChatTypeDirect  = "direct"  // A conversation between a user and the bot.
+	ChatTypePrivate = "private" // A private group.
+	ChatTypePublic  = "public"  // A public group, users can find and join it by its hashtag.
+)
+
+// IsGroupChat reports whether m was sent in a group, rather than a direct conversation with the bot.
+// Messages without a chatType are treated as group messages if they have more than one participant.
+func (m ReceiveMessage) IsGroupChat() bool {
+	if m.ChatType != "" {
+		return m.ChatType != ChatTypeDirect
+	}
+	return len(m.Participants) > 1
+}
+
+// MentionsBot reports whether m was sent by mentioning botUsername, e.g. "@bot hi" in a group.
+// Kik usernames are case insensitive.
+func (m ReceiveMessage) MentionsBot(botUsername string) bool {
+	return m.Mention != "" && strings.EqualFold(m.Mention, strings.TrimPrefix(botUsername, "@"))
+}
+
+// Attribution replaces the bot's name and icon shown on picture, video and link messages.
+// Set Preset to show the media as coming from the user's gallery or camera instead.
 type Attribution struct {
 	Name    string `json:"name"`              // The name that will appear in the attribution bar.
 	IconUrl string `json:"iconUrl,omitempty"` // BROKEN in KIK API: The URL specifying an icon that will appear in the attribution bar.
+
+	Preset string `json:"-"` // AttributionGallery or AttributionCamera, Name and IconUrl are ignored if set.
+}
+
+// Attribution presets, sent as a plain string instead of a name and icon.
+const (
+	AttributionGallery = "gallery"
+	AttributionCamera  = "camera"
+)
+
+func (a Attribution) MarshalJSON() ([]byte, error) {
+	if a.Preset != "" {
+		return json.Marshal(a.Preset)
+	}
+	type attribution Attribution // Avoids recursing into MarshalJSON.
+	return json.Marshal(attribution(a))
+}
+
+func (a *Attribution) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		*a = Attribution{}
+		return json.Unmarshal(data, &a.Preset)
+	}
+	type attribution Attribution // Avoids recursing into UnmarshalJSON.
+	return json.Unmarshal(data, (*attribution)(a))
 }
 
 // TextMessage for sending from the bot.
 type TextMessage struct {
 	SendMessage
-	Body     string `json:"body"`               // The text of the message.
-	TypeTime int    `json:"typeTime,omitempty"` // An interval (in milliseconds) to appear to be typing to the recipient before the message is sent. This occurs after delay.
+	Body string `json:"body"` // The text of the message.
+}
+
+// IsTypingMessage shows or hides the typing indicator of the bot.
+type IsTypingMessage struct {
+	SendMessage
+	IsTyping bool `json:"isTyping"` // Whether the bot should appear to be typing.
 }
 
 // TextMessageReceive is the data structure returned from the Kik API when a user sends the bot a text message.
@@ -202,6 +559,8 @@ type LinkMessageReceive struct {
 	Url string `json:"url"`
 
 	PicUrl      string       `json:"picUrl,omitempty"`    // A picture to be displayed in the message.
+	Title       string       `json:"title,omitempty"`     // A title to be displayed at the top of the message.
+	Text        string       `json:"text,omitempty"`      // Text to be displayed in the middle of the message.
 	NoForward   bool         `json:"noForward,omitempty"` //	If true, the message will not be able to be forwarded to other recipients.
 	KikJsData   string       `json:"kikJsData,omitempty"` //	A JSON payload that would be passed to a website using Kik.js.
 	Attribution *Attribution `json:"attribution,omitempty"`
@@ -223,6 +582,64 @@ type VideoMessageReceive struct {
 	Attribution *Attribution `json:"attribution,omitempty"`
 }
 
+// DeliveryReceiptReceive is sent by the Kik API when messages from the bot have been delivered to a user.
+// Requires the ReceiveDeliveryReceipts feature.
+type DeliveryReceiptReceive struct {
+	ReceiveMessage
+	MessageIds MessageIds `json:"messageIds"` // The IDs of the messages that were delivered.
+}
+
+// MessageIds are the ids of the messages a receipt is for, see AssignMessageIds.
+type MessageIds []string
+
+// Contains reports whether the receipt is for the message with id.
+func (ids MessageIds) Contains(id string) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadReceiptMessage tells a user their messages were read by the bot.
+// Only needed with the ManuallySendReadReceipts feature.
+type ReadReceiptMessage struct {
+	SendMessage
+	MessageIds []string `json:"messageIds"` // The IDs of the messages that were read.
+}
+
+// ReadReceiptReceive is sent by the Kik API when messages from the bot have been read by a user.
+// Requires the ReceiveReadReceipts feature.
+type ReadReceiptReceive struct {
+	ReceiveMessage
+	MessageIds MessageIds `json:"messageIds"` // The IDs of the messages that were read.
+}
+
+// IsTypingReceive is sent by the Kik API when a user starts or stops typing.
+// Requires the ReceiveIsTyping feature.
+type IsTypingReceive struct {
+	ReceiveMessage
+	IsTyping bool `json:"isTyping"` // Whether the user is typing.
+}
+
+// StartChattingReceive is sent by the Kik API when a user starts chatting with the bot for the first time.
+type StartChattingReceive struct {
+	ReceiveMessage
+}
+
+// ScanDataReceive is sent by the Kik API when a user scans a Kik code of the bot, see CreateCode.
+type ScanDataReceive struct {
+	ReceiveMessage
+	Data string `json:"data"` // The data embedded in the scanned Kik code.
+}
+
+// FriendPickerReceive is sent by the Kik API when a user picks friends using a KeyboardFriendPickerResponse.
+type FriendPickerReceive struct {
+	ReceiveMessage
+	Picked []string `json:"picked"` // The usernames of the friends that were picked.
+}
+
 /*
 Configuration
 */
@@ -234,6 +651,34 @@ type Configuration struct {
 	StaticKeyboard *SuggestedResponseKeyboard `json:"staticKeyboard,omitempty"` // A keyboard object that shows when a user starts to mention your bot in a conversation.
 }
 
+// Validate reports why Kik would reject the configuration, as an InvalidConfigurationError.
+// The webhook must be an absolute https URL, and a static keyboard must be a suggested keyboard with responses.
+func (c *Configuration) Validate() error {
+	u, err := url.Parse(c.Webhook)
+	switch {
+	case c.Webhook == "":
+		return fmt.Errorf("%w: webhook is required", InvalidConfigurationError)
+	case err != nil:
+		return fmt.Errorf("%w: webhook %q: %v", InvalidConfigurationError, c.Webhook, err)
+	case u.Scheme != "https":
+		return fmt.Errorf("%w: webhook %q must use https", InvalidConfigurationError, c.Webhook)
+	case u.Hostname() == "":
+		return fmt.Errorf("%w: webhook %q has no host", InvalidConfigurationError, c.Webhook)
+	case u.Fragment != "" || u.User != nil:
+		return fmt.Errorf("%w: webhook %q must not have a fragment or user info", InvalidConfigurationError, c.Webhook)
+	}
+
+	if k := c.StaticKeyboard; k != nil {
+		if k.Type != "suggested" {
+			return fmt.Errorf("%w: static keyboard type %q must be suggested", InvalidConfigurationError, k.Type)
+		}
+		if len(k.Responses) == 0 {
+			return fmt.Errorf("%w: static keyboard has no responses", InvalidConfigurationError)
+		}
+	}
+	return nil
+}
+
 type Features struct {
 	ManuallySendReadReceipts bool `json:"manuallySendReadReceipts"` // If enabled, your bot will be responsible for sending its own read receipts to users when you receive messages.
 	ReceiveReadReceipts      bool `json:"receiveReadReceipts"`      // If enabled, your bot will receive messages of type read-receipt messages from users.
@@ -255,13 +700,107 @@ type ScanData struct {
 	Data string `json:"data"` // Will be embedded in the Kik Code that users can scan.
 }
 
+// Code is the response body of CreateCode.
+// Kik only returns the code ID, codes carry no creation or expiry time and stay valid indefinitely.
+// Bots running expiring campaigns need to track expiry themselves, for example inside the ScanData.
 type Code struct {
 	Id string `json:"id"` // The ID to reference a generated Kik code.
 }
 
+// Url returns the URL of the image of the code in color, at DefaultBaseUrl.
+// Kik always renders codes as 1024x1024 PNGs, the size can not be chosen.
+func (c *Code) Url(color CodeColor) string {
+	return fmt.Sprintf("%s%s/%s?c=%d", DefaultBaseUrl, strings.TrimPrefix(CodeUrl, "/"), url.PathEscape(c.Id), color)
+}
+
+// CodeColor is the color of a rendered Kik code.
+type CodeColor int
+
+// The palette of Kik code colors.
+const (
+	CodeKikBlue CodeColor = iota
+	CodeTurquoise
+	CodeMint
+	CodeForest
+	CodeKikGreen
+	CodeSunshine
+	CodeOrangeCreamsicle
+	CodeBloodOrange
+	CodeCandyAppleRed
+	CodeSalmon
+	CodeCoral
+	CodeCranberry
+	CodeLavender
+	CodeRoyalPurple
+	CodeMarine
+	CodeSteel
+)
+
 /*
 Error Types
 */
 
 var NotMessageTypeError = errors.New("not a valid message type")
-var HttpError = errors.New("HTTP request did not return 200")
+var MissingFieldError = errors.New("message is missing a required field")
+var UnknownStickerPackError = errors.New("sticker pack is not allowed")
+var NoProfilePicError = errors.New("user has no profile picture")
+var NotEchoableError = errors.New("message type can not be echoed")
+var NotReceiptError = errors.New("not a delivery or read receipt")
+var MissingRecipientError = errors.New("message has no recipient")
+var InvalidTransitionError = errors.New("invalid delivery state transition")
+var InvalidSignatureError = errors.New("invalid webhook signature")
+var BodyTooLargeError = errors.New("webhook body is larger than MaxWebhookBodySize")
+var InvalidConfigurationError = errors.New("invalid bot configuration")
+var InvalidVideoError = errors.New("invalid video message")
+var InvalidMessageError = errors.New("invalid message")
+var InvalidScanDataError = errors.New("invalid scan data")
+var CircuitOpenError = errors.New("circuit breaker is open")
+var BufferFullError = errors.New("incoming message buffer is full")
+var HttpError = errors.New("HTTP request did not return 2xx")
+
+// APIError is returned when the Kik API responds with a non 2xx status.
+// It matches HttpError when using errors.Is.
+type APIError struct {
+	StatusCode int
+	Method     string
+	Url        string
+	Header     http.Header // The response headers.
+
+	Code    string // The error code from the Kik error body, e.g. "BadRequest".
+	Message string // The error message from the Kik error body.
+	Body    []byte // The raw response body.
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%v: %s %s returned: <%v> %s", HttpError, e.Method, e.Url, e.StatusCode, e.Body)
+}
+
+func (e *APIError) Unwrap() error { return HttpError }
+
+// IsRateLimited reports whether the request was rejected for exceeding Kik's rate limits.
+func (e *APIError) IsRateLimited() bool { return e.StatusCode == http.StatusTooManyRequests }
+
+// IsUnauthorized reports whether the bot username or API key were rejected.
+func (e *APIError) IsUnauthorized() bool { return e.StatusCode == http.StatusUnauthorized }
+
+// newAPIError reads the Kik error body from a failed response.
+func newAPIError(req *http.Request, resp *http.Response) *APIError {
+	b, _ := ioutil.ReadAll(resp.Body)
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Method:     req.Method,
+		Url:        req.URL.String(),
+		Body:       b,
+	}
+
+	var kikErr struct {
+		Error   string `json:"error"`
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(b, &kikErr) == nil {
+		apiErr.Code = kikErr.Error
+		apiErr.Message = kikErr.Message
+	}
+	return apiErr
+}
@@ -0,0 +1,30 @@
+package kik
+
+import "time"
+
+// WithTypingSimulation makes SendMessage show the typing indicator for typeTime before each message, see SimulateTyping.
+func WithTypingSimulation(typeTime time.Duration) Option {
+	return func(k *Client) error {
+		k.TypingSimulation = typeTime
+		return nil
+	}
+}
+
+// SimulateTyping returns a copy of messages where each text, picture, link and video message without a TypeTime
+// shows the typing indicator for typeTime before it is shown, so replies of many messages arrive at a natural pace.
+//
+//	client.SendMessage(ctx, kik.SimulateTyping(messages, 800*time.Millisecond))
+func SimulateTyping(messages []Message, typeTime time.Duration) []Message {
+	ms := int(typeTime / time.Millisecond)
+	simulated := make([]Message, len(messages))
+	for i, m := range messages {
+		simulated[i] = m
+		switch messageValue(m).(type) {
+		case TextMessage, PictureMessage, LinkMessage, VideoMessage:
+			if m.header().TypeTime == 0 {
+				simulated[i] = withHeader(m, func(s *SendMessage) { s.TypeTime = ms })
+			}
+		}
+	}
+	return simulated
+}
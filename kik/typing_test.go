@@ -0,0 +1,58 @@
+package kik_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/r-kells/go-kik/kik"
+	"github.com/r-kells/go-kik/kiktest"
+)
+
+func TestSimulateTyping(t *testing.T) {
+	messages := []kik.Message{
+		kik.TextMessage{SendMessage: kik.SendMessage{To: username, Type: "text"}, Body: "Hi"},
+		kik.TextMessage{SendMessage: kik.SendMessage{To: username, Type: "text", TypeTime: 100}, Body: "Bye"},
+		kik.IsTypingMessage{SendMessage: kik.SendMessage{To: username, Type: "is-typing"}, IsTyping: true},
+		&kik.LinkMessage{SendMessage: kik.SendMessage{To: username, Type: "link"}, Url: "https://example.com"},
+	}
+
+	got := kik.SimulateTyping(messages, 800*time.Millisecond)
+
+	if tt := got[0].(kik.TextMessage).TypeTime; tt != 800 {
+		t.Errorf("SimulateTyping()[0].TypeTime = %d; want 800", tt)
+	}
+	if tt := got[1].(kik.TextMessage).TypeTime; tt != 100 {
+		t.Errorf("SimulateTyping()[1].TypeTime = %d; want the TypeTime that was set", tt)
+	}
+	if tt := got[2].(kik.IsTypingMessage).TypeTime; tt != 0 {
+		t.Errorf("SimulateTyping()[2].TypeTime = %d; want is-typing messages unchanged", tt)
+	}
+	if tt := got[3].(*kik.LinkMessage).TypeTime; tt != 800 {
+		t.Errorf("SimulateTyping()[3].TypeTime = %d; want 800 for a pointer to a link message", tt)
+	}
+	if tt := messages[0].(kik.TextMessage).TypeTime; tt != 0 {
+		t.Errorf("SimulateTyping() modified messages, TypeTime = %d", tt)
+	}
+	if tt := messages[3].(*kik.LinkMessage).TypeTime; tt != 0 {
+		t.Errorf("SimulateTyping() modified the link message pointed to, TypeTime = %d", tt)
+	}
+}
+
+func TestWithTypingSimulation(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+	if err := kik.WithTypingSimulation(time.Second)(server.Client); err != nil {
+		t.Fatalf("WithTypingSimulation() returned an error = %+v; expected no error", err)
+	}
+
+	m, _ := kik.NewPicture("https://example.com/cat.png").To(username).Build()
+	if err := server.Client.SendMessage(context.Background(), []kik.Message{m}); err != nil {
+		t.Fatalf("SendMessage() returned an error = %+v; expected no error", err)
+	}
+
+	var sent kik.PictureMessage
+	if err := server.Messages()[0].Decode(&sent); err != nil || sent.TypeTime != 1000 {
+		t.Errorf("sent %+v, %v; want a TypeTime of 1000", sent, err)
+	}
+}
@@ -0,0 +1,149 @@
+package kik
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// DefaultUserCacheTTL is how long a Client caches profiles, if its UserCacheTTL is not set.
+const DefaultUserCacheTTL = 10 * time.Minute
+
+// DefaultUserCacheSize is the number of profiles a MemoryUserCache created with a size of 0 holds.
+const DefaultUserCacheSize = 10000
+
+// UserCache caches the profiles returned by GetUser, see Client.UserCache.
+type UserCache interface {
+	// Get returns the cached profile of username, the bool is false if it is not cached or expired.
+	Get(username string) (*User, bool)
+	// Set caches the profile of username for ttl.
+	Set(username string, user *User, ttl time.Duration)
+	// Delete removes the profile of username from the cache.
+	Delete(username string)
+}
+
+// CacheMetrics is implemented by Metrics that also record cache lookups, e.g. the kikmetrics Collector.
+type CacheMetrics interface {
+	// ObserveCache is called for every lookup of a cached endpoint, hit reports whether the API call was saved.
+	ObserveCache(endpoint string, hit bool)
+}
+
+// MemoryUserCache is an in-memory UserCache that is safe for concurrent use.
+// It holds at most Size profiles, evicting the least recently used one when full.
+type MemoryUserCache struct {
+	size int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List // Most recently used first.
+}
+
+type userCacheEntry struct {
+	username string
+	user     User
+	expiry   time.Time
+}
+
+// NewMemoryUserCache returns an empty MemoryUserCache holding up to size profiles,
+// a size of 0 or less holds DefaultUserCacheSize profiles.
+func NewMemoryUserCache(size int) *MemoryUserCache {
+	if size <= 0 {
+		size = DefaultUserCacheSize
+	}
+	return &MemoryUserCache{size: size, entries: make(map[string]*list.Element), lru: list.New()}
+}
+
+func (c *MemoryUserCache) Get(username string) (*User, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[username]
+	if !ok {
+		return nil, false
+	}
+	entry := e.Value.(*userCacheEntry)
+	if !time.Now().Before(entry.expiry) {
+		c.remove(e)
+		return nil, false
+	}
+	c.lru.MoveToFront(e)
+	user := entry.user
+	return &user, true
+}
+
+func (c *MemoryUserCache) Set(username string, user *User, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &userCacheEntry{username: username, user: *user, expiry: time.Now().Add(ttl)}
+	if e, ok := c.entries[username]; ok {
+		e.Value = entry
+		c.lru.MoveToFront(e)
+		return
+	}
+	c.entries[username] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.size {
+		c.remove(c.lru.Back())
+	}
+}
+
+func (c *MemoryUserCache) Delete(username string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[username]; ok {
+		c.remove(e)
+	}
+}
+
+// Len returns the number of cached profiles, including expired ones not evicted yet.
+func (c *MemoryUserCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// remove drops e from the cache, c.mu must be held.
+func (c *MemoryUserCache) remove(e *list.Element) {
+	c.lru.Remove(e)
+	delete(c.entries, e.Value.(*userCacheEntry).username)
+}
+
+// WithUserCache makes GetUser return profiles cached within ttl instead of calling the API, see Client.UserCache.
+func WithUserCache(cache UserCache, ttl time.Duration) Option {
+	return func(k *Client) error {
+		k.UserCache = cache
+		k.UserCacheTTL = ttl
+		return nil
+	}
+}
+
+// InvalidateUser removes the cached profile of username, so the next GetUser fetches it from the API.
+func (k *Client) InvalidateUser(username string) {
+	if k.UserCache != nil {
+		k.UserCache.Delete(username)
+	}
+}
+
+// cachedUser returns the cached profile of username, recording the lookup in the Metrics.
+func (k *Client) cachedUser(username string) (*User, bool) {
+	if k.UserCache == nil {
+		return nil, false
+	}
+	user, ok := k.UserCache.Get(username)
+	if m, isCacheMetrics := k.Metrics.(CacheMetrics); isCacheMetrics {
+		m.ObserveCache(EndpointUser, ok)
+	}
+	return user, ok
+}
+
+// cacheUser caches the profile of username for UserCacheTTL.
+func (k *Client) cacheUser(username string, user *User) {
+	if k.UserCache == nil {
+		return
+	}
+	ttl := k.UserCacheTTL
+	if ttl <= 0 {
+		ttl = DefaultUserCacheTTL
+	}
+	k.UserCache.Set(username, user, ttl)
+}
@@ -0,0 +1,84 @@
+package kik_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/r-kells/go-kik/kik"
+	"github.com/r-kells/go-kik/kiktest"
+)
+
+func TestGetUser_Cached(t *testing.T) {
+	client, mux, teardown := kiktest.TestClient(t)
+	defer teardown()
+
+	calls := 0
+	mux.HandleFunc(kik.GetUserUrl, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprintf(w, `{"firstName": "Ryan %d"}`, calls)
+	})
+	client.UserCache = kik.NewMemoryUserCache(0)
+
+	for i := 0; i < 3; i++ {
+		user, err := client.GetUser(context.Background(), username)
+		if err != nil {
+			t.Fatalf("GetUser(%s) returned an error = %+v; expected no error", username, err)
+		}
+		if user.FirstName != "Ryan 1" {
+			t.Errorf("GetUser(%s) = %+v; want the cached profile", username, user)
+		}
+		user.FirstName = "changed by the caller"
+	}
+	if calls != 1 {
+		t.Errorf("GetUser(%s) called the API %d times; want 1", username, calls)
+	}
+
+	client.InvalidateUser(username)
+	if user, _ := client.GetUser(context.Background(), username); user == nil || user.FirstName != "Ryan 2" {
+		t.Errorf("GetUser(%s) after InvalidateUser = %+v; want a fresh profile", username, user)
+	}
+}
+
+func TestGetUser_CacheExpires(t *testing.T) {
+	client, mux, teardown := kiktest.TestClient(t)
+	defer teardown()
+
+	calls := 0
+	mux.HandleFunc(kik.GetUserUrl, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprint(w, `{}`)
+	})
+	client.UserCache = kik.NewMemoryUserCache(0)
+	client.UserCacheTTL = time.Millisecond
+
+	client.GetUser(context.Background(), username)
+	time.Sleep(5 * time.Millisecond)
+	client.GetUser(context.Background(), username)
+
+	if calls != 2 {
+		t.Errorf("GetUser(%s) called the API %d times; want 2 after the TTL elapsed", username, calls)
+	}
+}
+
+func TestMemoryUserCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache := kik.NewMemoryUserCache(2)
+	cache.Set("a", &kik.User{FirstName: "A"}, time.Minute)
+	cache.Set("b", &kik.User{FirstName: "B"}, time.Minute)
+	cache.Get("a")
+	cache.Set("c", &kik.User{FirstName: "C"}, time.Minute)
+
+	if _, ok := cache.Get("b"); ok {
+		t.Errorf("Get(b) is cached; want it evicted")
+	}
+	for _, name := range []string{"a", "c"} {
+		if _, ok := cache.Get(name); !ok {
+			t.Errorf("Get(%s) is not cached; want it kept", name)
+		}
+	}
+	if cache.Len() != 2 {
+		t.Errorf("Len() = %d; want 2", cache.Len())
+	}
+}
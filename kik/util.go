@@ -2,42 +2,154 @@ package kik
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"io/ioutil"
-	"log"
 	"net/http"
+	"strings"
+	"time"
 )
 
-func (k *Client) do(req *http.Request, v interface{}) error {
+// call sends an authenticated request to the Kik API and decodes the response into v, if v is not nil.
+// Failed attempts are retried according to the Client's RetryPolicy, each attempt is paced by its RateLimiter.
+// Credentials the API rejects are refreshed once, if the CredentialsProvider can be invalidated.
+func (k *Client) call(ctx context.Context, method, urlStr string, body interface{}, v interface{}) error {
+	refreshed := false
+	for attempt := 1; ; attempt++ {
+		if k.RateLimiter != nil {
+			if err := k.RateLimiter.Wait(ctx); err != nil {
+				return err
+			}
+		}
+
+		attemptCtx, cancel := k.attemptContext(ctx, urlStr)
+		req, err := k.newRequest(attemptCtx, method, urlStr, body)
+		if err != nil {
+			cancel()
+			k.onError(nil, err)
+			return err
+		}
+		if k.Breaker != nil {
+			if err := k.Breaker.allow(endpoint(urlStr)); err != nil {
+				cancel()
+				k.onError(req, err)
+				return err
+			}
+		}
+		k.onRequest(req, body)
+
+		start := time.Now()
+		resp, err := k.do(req, v)
+		duration := time.Since(start)
+		cancel()
+		if k.Breaker != nil {
+			k.Breaker.record(ctx, endpoint(urlStr), err)
+		}
+
+		statusCode := 0
+		if resp != nil {
+			statusCode = resp.StatusCode
+			k.debug("kik response", "method", method, "url", req.URL.String(),
+				"status", statusCode, "duration", duration)
+		}
+		if k.Metrics != nil {
+			k.Metrics.ObserveRequest(endpoint(urlStr), statusCode, duration, err)
+		}
+		if err == nil {
+			k.onResponse(req, resp, v)
+			return nil
+		}
+		k.onError(req, err)
+
+		if !refreshed && k.refreshCredentials(err) {
+			refreshed = true
+			k.debug("kik refreshing credentials", "method", method, "url", req.URL.String())
+			continue
+		}
+		delay, retry := k.RetryPolicy.retry(ctx, attempt, err)
+		if !retry || (!hasResponse(err) && !k.resendable(method, urlStr, body)) {
+			return err
+		}
+		k.debug("kik retry", "method", method, "url", req.URL.String(),
+			"attempt", attempt, "delay", delay, "error", err)
+		if k.Metrics != nil {
+			k.Metrics.ObserveRetry(endpoint(urlStr))
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// hasResponse reports whether the request that failed with err got a response from the Kik API.
+func hasResponse(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr)
+}
+
+// resendable reports whether a request that failed without a response may be sent again,
+// as Kik may have received it, see RetryPolicy.RetryNetworkErrors.
+func (k *Client) resendable(method, urlStr string, body interface{}) bool {
+	if method != http.MethodPost {
+		return true
+	}
+	switch endpoint(urlStr) {
+	case EndpointConfig:
+		return true
+	case EndpointSend:
+		messages, ok := body.(Messages)
+		if !ok || k.Dedupe == nil {
+			return false
+		}
+		for _, m := range messages.Messages {
+			if m.header().Id == "" {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// attemptContext returns the context of a single attempt of a request to urlStr, bounded by its endpoint timeout.
+func (k *Client) attemptContext(ctx context.Context, urlStr string) (context.Context, context.CancelFunc) {
+	if timeout, ok := k.EndpointTimeouts[endpoint(urlStr)]; ok && timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return ctx, func() {}
+}
+
+// do sends the request and decodes the response body into v, if v is not nil.
+// Any 2xx status is a success, an empty body leaves v untouched.
+// The returned response has its body closed already.
+func (k *Client) do(req *http.Request, v interface{}) (*http.Response, error) {
 	resp, err := k.Client.Do(req)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
+	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		defer resp.Body.Close()
-		b, _ := ioutil.ReadAll(resp.Body)
-		return fmt.Errorf("%v: %s %s %s returned: <%v> %s",
-			HttpError, req.Method, req.URL, req.Body, resp.StatusCode, b)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp, newAPIError(req, resp)
 	}
 
 	if v != nil {
-		defer resp.Body.Close()
-
-		if err = json.NewDecoder(resp.Body).Decode(v); err != nil {
-			return fmt.Errorf("error trying to decode json into struct: %v", err)
+		err = k.codec().Decode(resp.Body, v)
+		if err != nil && err != io.EOF {
+			return resp, fmt.Errorf("error trying to decode json into struct: %v", err)
 		}
 	}
-	return nil
+	return resp, nil
 }
 
-// newRequest creates an http.Request. A relative URL is resolved relative to the BaseURL of the Client.
+// newRequest creates an authenticated http.Request. A relative URL is resolved relative to the BaseURL of the Client.
 // Relative URLs should always be specified with a preceding slash.
 // If specified, the value pointed to by body is JSON encoded and included as the request body.
-func (k *Client) newRequest(method, urlStr string, body interface{}) (*http.Request, error) {
+// The request is cancelled when ctx is done.
+func (k *Client) newRequest(ctx context.Context, method, urlStr string, body interface{}) (*http.Request, error) {
 
 	parsedUrl, err := k.BaseUrl.Parse(urlStr)
 	if err != nil {
@@ -46,24 +158,55 @@ func (k *Client) newRequest(method, urlStr string, body interface{}) (*http.Requ
 
 	var buf io.ReadWriter
 	if body != nil {
-		buf = new(bytes.Buffer)
-		enc := json.NewEncoder(buf)
-		enc.SetEscapeHTML(false)
-		err := enc.Encode(body)
+		b := new(bytes.Buffer)
+		err := k.codec().Encode(b, body)
 		if err != nil {
 			return nil, err
 		}
+		k.debug("kik request", "method", method, "url", parsedUrl.String(), "body", strings.TrimSuffix(b.String(), "\n"))
+		buf = b
+	} else {
+		k.debug("kik request", "method", method, "url", parsedUrl.String())
 	}
 
-	log.Printf("%s %s %s", method, parsedUrl.String(), buf)
-
-	req, err := http.NewRequest(method, parsedUrl.String(), buf)
+	req, err := http.NewRequestWithContext(ctx, method, parsedUrl.String(), buf)
 	if err != nil {
 		return nil, err
 	}
 
+	k.setHeaders(req)
 	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
+	username, apiKey, err := k.credentials(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting the credentials of the bot: %w", err)
+	}
+	req.SetBasicAuth(username, apiKey)
 	return req, nil
 }
+
+// setHeaders adds the Header and UserAgent of the Client to req.
+func (k *Client) setHeaders(req *http.Request) {
+	for key, values := range k.Header {
+		req.Header[key] = append([]string(nil), values...)
+	}
+	if k.UserAgent != "" && req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", k.UserAgent)
+	}
+}
+
+// encodeJSON writes v to w the way request bodies are sent to the Kik API.
+func encodeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return enc.Encode(v)
+}
+
+// countingWriter counts the bytes written to it and discards them.
+type countingWriter int
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	*c += countingWriter(len(p))
+	return len(p), nil
+}
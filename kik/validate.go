@@ -0,0 +1,220 @@
+package kik
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"unicode/utf8"
+)
+
+const (
+	// MaxTextLength is the longest body, in characters, of a text message Kik accepts,
+	// see https://dev.kik.com/#/docs/messaging.
+	MaxTextLength = 1000
+	// MinFriendPicks and MaxFriendPicks bound the Min and Max of a KeyboardFriendPickerResponse,
+	// see https://dev.kik.com/#/docs/messaging#keyboards.
+	MinFriendPicks = 1
+	MaxFriendPicks = 100
+)
+
+// ValidationError describes a field of an outgoing message Kik would reject.
+// It matches InvalidMessageError when using errors.Is, and MissingRecipientError if the "to" field is missing.
+type ValidationError struct {
+	Index  int    // The index of the message, see ValidateMessages.
+	Type   string // The type of the message.
+	Field  string // The JSON path of the field, e.g. "body" or "keyboards[0].responses[1].min".
+	Reason string
+	Err    error // The error of a type specific check instead of Field and Reason, e.g. of VideoMessage.Validate.
+}
+
+func (e *ValidationError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("invalid %s message %d: %v", e.Type, e.Index, e.Err)
+	}
+	return fmt.Sprintf("invalid %s message %d: %s %s", e.Type, e.Index, e.Field, e.Reason)
+}
+
+func (e *ValidationError) Unwrap() error { return e.Err }
+
+func (e *ValidationError) Is(target error) bool {
+	return target == InvalidMessageError || (target == MissingRecipientError && e.Field == "to")
+}
+
+// WithValidation makes SendMessage and BroadcastMessage check messages with ValidateMessages before sending them.
+func WithValidation() Option {
+	return func(k *Client) error {
+		k.Validate = true
+		return nil
+	}
+}
+
+// ValidateMessages checks messages against the documented limits of the Kik API, see ValidateMessage.
+// The returned *ValidationError has the Index of the first invalid message.
+func ValidateMessages(messages []Message) error {
+	for i, m := range messages {
+		if err := validateMessage(i, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateMessage checks m against the documented limits of the Kik API, before sending it results in a 400.
+// Every message needs a recipient and a type matching its Go type, a non negative delay and typeTime, and valid keyboards.
+// Text needs a body of at most MaxTextLength characters, pictures a URL, and read receipts message ids.
+// Links need an http or https URL, their kikJsData must be JSON.
+// Video messages are checked by VideoMessage.Validate. Problems are returned as a *ValidationError,
+// with the error of VideoMessage.Validate as its Err.
+func ValidateMessage(m Message) error {
+	return validateMessage(0, m)
+}
+
+func validateMessage(i int, m Message) error {
+	m = messageValue(m)
+	h := m.header()
+	invalid := func(field, reason string, args ...interface{}) error {
+		return &ValidationError{Index: i, Type: h.Type, Field: field, Reason: fmt.Sprintf(reason, args...)}
+	}
+
+	if want := messageType(m); h.Type != want {
+		return invalid("type", "is %q, want %q", h.Type, want)
+	}
+	if h.To == "" {
+		return invalid("to", "is required")
+	}
+	if h.Delay < 0 {
+		return invalid("delay", "is %d, must not be negative", h.Delay)
+	}
+	if h.TypeTime < 0 {
+		return invalid("typeTime", "is %d, must not be negative", h.TypeTime)
+	}
+	for k, keyboard := range h.Keyboards {
+		if field, reason := validateKeyboard(keyboard); field != "" {
+			return invalid(fmt.Sprintf("keyboards[%d]%s", k, field), "%s", reason)
+		}
+	}
+
+	switch m := m.(type) {
+	case TextMessage:
+		if m.Body == "" {
+			return invalid("body", "is required")
+		}
+		if n := utf8.RuneCountInString(m.Body); n > MaxTextLength {
+			return invalid("body", "is %d characters, longer than %d", n, MaxTextLength)
+		}
+	case PictureMessage:
+		if m.PicUrl == "" {
+			return invalid("picUrl", "is required")
+		}
+	case LinkMessage:
+		if m.Url == "" {
+			return invalid("url", "is required")
+		}
+		if !isHttpUrl(m.Url) {
+			return invalid("url", "%q must be an absolute http or https URL", m.Url)
+		}
+		if m.PicUrl != "" && !isHttpUrl(m.PicUrl) {
+			return invalid("picUrl", "%q must be an absolute http or https URL", m.PicUrl)
+		}
+		if m.KikJsData != "" && !json.Valid([]byte(m.KikJsData)) {
+			return invalid("kikJsData", "is not valid JSON")
+		}
+	case VideoMessage:
+		if err := m.Validate(); err != nil {
+			return &ValidationError{Index: i, Type: h.Type, Err: err}
+		}
+	case ReadReceiptMessage:
+		if len(m.MessageIds) == 0 {
+			return invalid("messageIds", "is required")
+		}
+	}
+	return nil
+}
+
+// isHttpUrl reports whether s is an absolute http or https URL, as Kik requires for links and media.
+func isHttpUrl(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// validateKeyboard returns the path of the invalid field relative to the keyboard and the reason, or "" if k is valid.
+func validateKeyboard(k SuggestedResponseKeyboard) (string, string) {
+	invalid := func(field, reason string, args ...interface{}) (string, string) {
+		return field, fmt.Sprintf(reason, args...)
+	}
+
+	if k.Type != "suggested" {
+		return invalid(".type", "is %q, want %q", k.Type, "suggested")
+	}
+	if len(k.Responses) == 0 {
+		return invalid(".responses", "is required")
+	}
+
+	seenText := false
+	for r, response := range k.Responses {
+		field := fmt.Sprintf(".responses[%d]", r)
+		switch response := response.(type) {
+		case KeyboardTextResponse:
+			seenText = true
+			if response.Body == "" {
+				return invalid(field+".body", "is required")
+			}
+		case KeyboardPictureResponse:
+			if response.PicUrl == "" {
+				return invalid(field+".picUrl", "is required")
+			}
+		case KeyboardFriendPickerResponse:
+			if seenText {
+				return invalid(field, "must come before the text responses")
+			}
+			if response.Min != 0 && (response.Min < MinFriendPicks || response.Min > MaxFriendPicks) {
+				return invalid(field+".min", "is %d, must be between %d and %d", response.Min, MinFriendPicks, MaxFriendPicks)
+			}
+			if response.Max != 0 && (response.Max < MinFriendPicks || response.Max > MaxFriendPicks) {
+				return invalid(field+".max", "is %d, must be between %d and %d", response.Max, MinFriendPicks, MaxFriendPicks)
+			}
+			if response.Min != 0 && response.Max != 0 && response.Min > response.Max {
+				return invalid(field+".min", "is %d, greater than max %d", response.Min, response.Max)
+			}
+		}
+	}
+	return "", ""
+}
+
+// messageValue returns the message m points to, if m is a pointer to a message type of this package.
+func messageValue(m Message) Message {
+	switch m := m.(type) {
+	case *TextMessage:
+		return *m
+	case *PictureMessage:
+		return *m
+	case *LinkMessage:
+		return *m
+	case *VideoMessage:
+		return *m
+	case *IsTypingMessage:
+		return *m
+	case *ReadReceiptMessage:
+		return *m
+	}
+	return m
+}
+
+// messageType returns the type an outgoing message of m's Go type must have.
+func messageType(m Message) string {
+	switch m.(type) {
+	case TextMessage:
+		return "text"
+	case PictureMessage:
+		return "picture"
+	case LinkMessage:
+		return "link"
+	case VideoMessage:
+		return "video"
+	case IsTypingMessage:
+		return "is-typing"
+	case ReadReceiptMessage:
+		return "read-receipt"
+	}
+	return m.header().Type
+}
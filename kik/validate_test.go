@@ -0,0 +1,119 @@
+package kik_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/r-kells/go-kik/kik"
+	"github.com/r-kells/go-kik/kiktest"
+)
+
+func TestValidateMessage(t *testing.T) {
+	text := func(body string) kik.TextMessage {
+		return kik.TextMessage{SendMessage: kik.SendMessage{To: username, Type: "text"}, Body: body}
+	}
+	withKeyboard := func(responses ...kik.SuggestedResponse) kik.TextMessage {
+		m := text("Pick one")
+		m.Keyboards = []kik.SuggestedResponseKeyboard{{Type: "suggested", Responses: responses}}
+		return m
+	}
+	friendPicker := func(min, max int8) kik.SuggestedResponse {
+		return kik.KeyboardFriendPickerResponse{Type: "friend-picker", Min: min, Max: max}
+	}
+	yes := kik.KeyboardTextResponse{Type: "text", Body: "Yes"}
+	link := func(m kik.LinkMessage) kik.LinkMessage {
+		m.SendMessage = kik.SendMessage{To: username, Type: "link"}
+		return m
+	}
+
+	tests := []struct {
+		name    string
+		message kik.Message
+		field   string // "" if the message is valid.
+	}{
+		{"text", text("Hi"), ""},
+		{"wrong type", kik.TextMessage{SendMessage: kik.SendMessage{To: username, Type: "link"}, Body: "Hi"}, "type"},
+		{"no recipient", kik.TextMessage{SendMessage: kik.SendMessage{Type: "text"}, Body: "Hi"}, "to"},
+		{"negative delay", kik.TextMessage{SendMessage: kik.SendMessage{To: username, Type: "text", Delay: -1}, Body: "Hi"}, "delay"},
+		{"empty body", text(""), "body"},
+		{"long body", text(strings.Repeat("a", kik.MaxTextLength+1)), "body"},
+		{"negative type time", kik.TextMessage{SendMessage: kik.SendMessage{To: username, Type: "text", TypeTime: -5}, Body: "Hi"}, "typeTime"},
+		{"no picture", kik.PictureMessage{SendMessage: kik.SendMessage{To: username, Type: "picture"}}, "picUrl"},
+		{"pointer", &kik.PictureMessage{SendMessage: kik.SendMessage{To: username, Type: "picture"}}, "picUrl"},
+		{"no url", kik.LinkMessage{SendMessage: kik.SendMessage{To: username, Type: "link"}}, "url"},
+		{"link", link(kik.LinkMessage{Url: "https://example.com", PicUrl: "https://example.com/a.png", Title: "Example", KikJsData: `{"page": 2}`}), ""},
+		{"relative url", link(kik.LinkMessage{Url: "/pricing"}), "url"},
+		{"javascript url", link(kik.LinkMessage{Url: "javascript:alert(1)"}), "url"},
+		{"relative picture", link(kik.LinkMessage{Url: "https://example.com", PicUrl: "a.png"}), "picUrl"},
+		{"invalid kikJsData", link(kik.LinkMessage{Url: "https://example.com", KikJsData: "{page: 2}"}), "kikJsData"},
+		{"no message ids", kik.ReadReceiptMessage{SendMessage: kik.SendMessage{To: username, Type: "read-receipt"}}, "messageIds"},
+		{"is typing", kik.IsTypingMessage{SendMessage: kik.SendMessage{To: username, Type: "is-typing"}, IsTyping: true}, ""},
+		{"keyboard", withKeyboard(friendPicker(1, 5), yes), ""},
+		{"empty keyboard", withKeyboard(), "keyboards[0].responses"},
+		{"friend picker after text", withKeyboard(yes, friendPicker(1, 5)), "keyboards[0].responses[1]"},
+		{"friend picker max", withKeyboard(friendPicker(1, 101)), "keyboards[0].responses[0].max"},
+		{"friend picker min above max", withKeyboard(friendPicker(5, 2)), "keyboards[0].responses[0].min"},
+		{"empty response", withKeyboard(kik.KeyboardTextResponse{Type: "text"}), "keyboards[0].responses[0].body"},
+	}
+	for _, test := range tests {
+		err := kik.ValidateMessage(test.message)
+		var validationErr *kik.ValidationError
+		switch {
+		case test.field == "" && err != nil:
+			t.Errorf("ValidateMessage(%s) returned an error = %+v; expected no error", test.name, err)
+		case test.field != "" && (!errors.As(err, &validationErr) || validationErr.Field != test.field):
+			t.Errorf("ValidateMessage(%s) = %v; want an error for %s", test.name, err, test.field)
+		case test.field != "" && !errors.Is(err, kik.InvalidMessageError):
+			t.Errorf("ValidateMessage(%s) = %v; want it to match InvalidMessageError", test.name, err)
+		}
+	}
+}
+
+func TestValidateMessages_Index(t *testing.T) {
+	err := kik.ValidateMessages([]kik.Message{
+		kik.TextMessage{SendMessage: kik.SendMessage{To: username, Type: "text"}, Body: "Hi"},
+		kik.TextMessage{SendMessage: kik.SendMessage{Type: "text"}, Body: "Hi"},
+	})
+
+	var validationErr *kik.ValidationError
+	if !errors.As(err, &validationErr) || validationErr.Index != 1 || !errors.Is(err, kik.MissingRecipientError) {
+		t.Fatalf("ValidateMessages() = %v; want a missing recipient at index 1", err)
+	}
+	if want := "invalid text message 1: to is required"; err.Error() != want {
+		t.Errorf("Error() = %q; want %q", err.Error(), want)
+	}
+}
+
+func TestValidateMessages_Video(t *testing.T) {
+	err := kik.ValidateMessages([]kik.Message{
+		kik.TextMessage{SendMessage: kik.SendMessage{To: username, Type: "text"}, Body: "Hi"},
+		kik.VideoMessage{SendMessage: kik.SendMessage{To: username, Type: "video"}, VideoUrl: "cat.mp4"},
+	})
+
+	var validationErr *kik.ValidationError
+	if !errors.As(err, &validationErr) || validationErr.Index != 1 {
+		t.Fatalf("ValidateMessages() = %v; want a ValidationError at index 1", err)
+	}
+	if !errors.Is(err, kik.InvalidVideoError) || !errors.Is(err, kik.InvalidMessageError) {
+		t.Errorf("ValidateMessages() = %v; want it to match InvalidVideoError and InvalidMessageError", err)
+	}
+}
+
+func TestSendMessage_Validation(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+	server.Client.Validate = true
+
+	err := server.Client.SendMessage(context.Background(), []kik.Message{
+		kik.TextMessage{SendMessage: kik.SendMessage{To: username, Type: "text"}, Body: strings.Repeat("a", kik.MaxTextLength+1)},
+	})
+
+	if !errors.Is(err, kik.InvalidMessageError) {
+		t.Errorf("SendMessage() = %v; want an InvalidMessageError", err)
+	}
+	if sent := server.Messages(); len(sent) != 0 {
+		t.Errorf("SendMessage() sent %+v; want nothing sent", sent)
+	}
+}
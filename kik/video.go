@@ -0,0 +1,62 @@
+package kik
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// MaxVideoSize is the largest video file Kik accepts.
+	MaxVideoSize = 15 << 20
+	// MaxAutoplayVideoSize is the largest video Kik plays inline, larger videos with Autoplay set have to be tapped.
+	MaxAutoplayVideoSize = 1 << 20
+)
+
+// Validate reports why Kik would reject the video message, as an InvalidVideoError.
+// The VideoUrl must be an absolute http or https URL, Kik downloads the video from it.
+func (m VideoMessage) Validate() error {
+	if m.VideoUrl == "" {
+		return fmt.Errorf("%w: videoUrl is required", InvalidVideoError)
+	}
+	if !isHttpUrl(m.VideoUrl) {
+		return fmt.Errorf("%w: videoUrl %q must be an absolute http or https URL", InvalidVideoError, m.VideoUrl)
+	}
+	return nil
+}
+
+// Uploader hosts a local file, e.g. on a CDN or object storage, and returns the URL it can be downloaded from.
+// Kik only sends media from hosted URLs.
+type Uploader func(ctx context.Context, name string, size int64, r io.Reader) (string, error)
+
+// SendVideoFile uploads the video file at path with upload, then sends message with the VideoUrl set to the uploaded file.
+// The file may be at most MaxVideoSize.
+func (k *Client) SendVideoFile(ctx context.Context, path string, upload Uploader, message VideoMessage) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() > MaxVideoSize {
+		return fmt.Errorf("%w: %s is %d bytes, larger than %d", InvalidVideoError, path, info.Size(), MaxVideoSize)
+	}
+
+	message.VideoUrl, err = upload(ctx, filepath.Base(path), info.Size(), f)
+	if err != nil {
+		return fmt.Errorf("error uploading %s: %w", path, err)
+	}
+	if message.Type == "" {
+		message.Type = "video"
+	}
+	if err := message.Validate(); err != nil {
+		return err
+	}
+	return k.SendMessage(ctx, []Message{message})
+}
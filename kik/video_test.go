@@ -0,0 +1,106 @@
+package kik_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/r-kells/go-kik/kik"
+	"github.com/r-kells/go-kik/kiktest"
+)
+
+func TestVideoMessage_Validate(t *testing.T) {
+	tests := []struct {
+		videoUrl string
+		wantErr  bool
+	}{
+		{"https://example.com/video.mp4", false},
+		{"http://example.com/video.mp4", false},
+		{"", true},
+		{"/video.mp4", true},
+		{"ftp://example.com/video.mp4", true},
+	}
+	for _, test := range tests {
+		err := kik.VideoMessage{VideoUrl: test.videoUrl}.Validate()
+		if (err != nil) != test.wantErr || (err != nil && !errors.Is(err, kik.InvalidVideoError)) {
+			t.Errorf("Validate(%s) = %v; want error = %v", test.videoUrl, err, test.wantErr)
+		}
+	}
+}
+
+func writeVideo(t *testing.T, size int) (string, func()) {
+	dir, err := ioutil.TempDir("", "kik")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "video.mp4")
+	if err := ioutil.WriteFile(path, make([]byte, size), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path, func() { os.RemoveAll(dir) }
+}
+
+func TestSendVideoFile_HappyPath(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+	path, cleanup := writeVideo(t, 1024)
+	defer cleanup()
+
+	var uploaded int64
+	upload := func(ctx context.Context, name string, size int64, r io.Reader) (string, error) {
+		uploaded, _ = io.Copy(ioutil.Discard, r)
+		return "https://cdn.example.com/" + name, nil
+	}
+	message := kik.VideoMessage{SendMessage: kik.SendMessage{To: username, ChatId: "c1"}, Loop: true}
+
+	if err := server.Client.SendVideoFile(context.Background(), path, upload, message); err != nil {
+		t.Fatalf("SendVideoFile() returned an error = %+v; expected no error", err)
+	}
+
+	sent := server.Messages()
+	var got kik.VideoMessage
+	if len(sent) != 1 || sent[0].Decode(&got) != nil {
+		t.Fatalf("SendVideoFile() sent %+v; want one message", sent)
+	}
+	if uploaded != 1024 || got.Type != "video" || got.VideoUrl != "https://cdn.example.com/video.mp4" || !got.Loop {
+		t.Errorf("SendVideoFile() uploaded %d bytes and sent %+v; want the uploaded video", uploaded, got)
+	}
+}
+
+func TestSendVideoFile_TooLarge(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+	path, cleanup := writeVideo(t, kik.MaxVideoSize+1)
+	defer cleanup()
+
+	upload := func(ctx context.Context, name string, size int64, r io.Reader) (string, error) {
+		t.Errorf("SendVideoFile() uploaded a video larger than MaxVideoSize")
+		return "", nil
+	}
+	err := server.Client.SendVideoFile(context.Background(), path, upload, kik.VideoMessage{SendMessage: kik.SendMessage{To: username}})
+
+	if !errors.Is(err, kik.InvalidVideoError) {
+		t.Errorf("SendVideoFile() = %v; want an InvalidVideoError", err)
+	}
+}
+
+func TestSendVideoFile_UploadFails(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+	path, cleanup := writeVideo(t, 1024)
+	defer cleanup()
+
+	wantErr := errors.New("upload failed")
+	upload := func(ctx context.Context, name string, size int64, r io.Reader) (string, error) {
+		return "", wantErr
+	}
+	err := server.Client.SendVideoFile(context.Background(), path, upload, kik.VideoMessage{SendMessage: kik.SendMessage{To: username}})
+
+	if !errors.Is(err, wantErr) || len(server.Messages()) != 0 {
+		t.Errorf("SendVideoFile() = %v; want the upload error and nothing sent", err)
+	}
+}
@@ -0,0 +1,117 @@
+package kik
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sync"
+)
+
+// SignatureHeader is the header Kik sends the HMAC signature of a webhook body in.
+const SignatureHeader = "X-Kik-Signature"
+
+// MaxWebhookBodySize is the largest webhook request body read, larger ones are rejected with BodyTooLargeError.
+const MaxWebhookBodySize = 1 << 20
+
+// maxPooledBodySize is the largest body buffer kept for the next webhook, so a rare large payload does not
+// keep its buffer alive.
+const maxPooledBodySize = 64 << 10
+
+// webhookBuffers are the body and messages of a webhook, reused by the next one.
+type webhookBuffers struct {
+	body     bytes.Buffer
+	messages ReceivedMessages
+}
+
+var webhookPool = sync.Pool{New: func() interface{} { return new(webhookBuffers) }}
+
+// MessageHandler handles a single incoming message.
+// Returning an error responds to Kik with a 500, so the whole payload is delivered again.
+type MessageHandler func(ctx context.Context, m Receive) error
+
+// WebhookHandler is an http.Handler for the webhook configured with Kik.
+// It verifies the signature of each request, parses the messages and dispatches them in order to Handler.
+// The body of the request is only readable again until ServeHTTP returns, r.Body is empty afterwards.
+type WebhookHandler struct {
+	Client  *Client
+	Handler MessageHandler
+
+	// OnError is called, if set, for invalid requests, messages that can not be parsed and errors from Handler.
+	OnError func(r *http.Request, err error)
+}
+
+// NewWebhookHandler returns a WebhookHandler verifying requests with k and dispatching messages to h.
+func NewWebhookHandler(k *Client, h MessageHandler) *WebhookHandler {
+	return &WebhookHandler{Client: k, Handler: h}
+}
+
+func (wh *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Handlers return before the buffers are reused, messages are decoded into their own memory.
+	buf := webhookPool.Get().(*webhookBuffers)
+	defer putWebhookBuffers(buf)
+
+	body, err := wh.Client.verifyRequest(r, &buf.body)
+	if replay, ok := r.Body.(*requestBody); ok {
+		// r.Body reads the pooled buffer, it is emptied before the next webhook reuses the buffer.
+		defer replay.Reset(nil)
+	}
+	if err == InvalidSignatureError {
+		wh.error(r, err)
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+	if err == BodyTooLargeError {
+		wh.error(r, err)
+		http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		wh.error(r, err)
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	messages, parseErrs, err := parseIncomingMessages(wh.Client.codec(), body, buf.messages[:0])
+	buf.messages = messages
+	if err != nil {
+		wh.error(r, err)
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	// Redelivering would not fix a malformed message, so these are only reported.
+	for _, err := range parseErrs {
+		wh.error(r, err)
+	}
+
+	for _, m := range messages {
+		if err := wh.Handler(r.Context(), m); err != nil {
+			wh.error(r, err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func putWebhookBuffers(buf *webhookBuffers) {
+	if buf.body.Cap() > maxPooledBodySize {
+		return
+	}
+	buf.body.Reset()
+	for i := range buf.messages {
+		buf.messages[i] = nil // The messages may outlive the webhook, e.g. in Bot.Messages.
+	}
+	webhookPool.Put(buf)
+}
+
+func (wh *WebhookHandler) error(r *http.Request, err error) {
+	if wh.OnError != nil {
+		wh.OnError(r, err)
+	}
+}
@@ -0,0 +1,230 @@
+package kik_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/r-kells/go-kik/kik"
+	"github.com/r-kells/go-kik/kiktest"
+)
+
+const textPayload = `{"messages": [
+	{"chatId": "0ee6d467", "id": "1", "type": "text", "from": "laura", "body": "Hi"},
+	{"chatId": "0ee6d467", "id": "2", "type": "text", "from": "laura", "body": "Are you there?"}
+]}`
+
+func TestWebhookHandler_HappyPath(t *testing.T) {
+	client, _, teardown := kiktest.TestClient(t)
+	defer teardown()
+
+	var got []string
+	handler := kik.NewWebhookHandler(client, func(ctx context.Context, m kik.Receive) error {
+		got = append(got, m.(*kik.TextMessageReceive).Body)
+		return nil
+	})
+
+	rec := postWebhook(handler, sign(client.ApiKey, []byte(textPayload)), textPayload)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("ServeHTTP() status = %d; want %d", rec.Code, http.StatusOK)
+	}
+	if len(got) != 2 || got[0] != "Hi" || got[1] != "Are you there?" {
+		t.Errorf("dispatched %v; want both messages in order", got)
+	}
+}
+
+func TestWebhookHandler_InvalidSignature(t *testing.T) {
+	client, _, teardown := kiktest.TestClient(t)
+	defer teardown()
+
+	var gotErr error
+	handler := kik.NewWebhookHandler(client, func(ctx context.Context, m kik.Receive) error {
+		t.Errorf("Handler should not be called for an invalid signature")
+		return nil
+	})
+	handler.OnError = func(r *http.Request, err error) { gotErr = err }
+
+	for _, signature := range []string{"", sign("wrong key", []byte(textPayload))} {
+		rec := postWebhook(handler, signature, textPayload)
+
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("ServeHTTP() status = %d; want %d", rec.Code, http.StatusForbidden)
+		}
+		if gotErr != kik.InvalidSignatureError {
+			t.Errorf("OnError got %v; want InvalidSignatureError", gotErr)
+		}
+	}
+}
+
+func TestWebhookHandler_HandlerError(t *testing.T) {
+	client, _, teardown := kiktest.TestClient(t)
+	defer teardown()
+
+	handler := kik.NewWebhookHandler(client, func(ctx context.Context, m kik.Receive) error {
+		return errors.New("database is down")
+	})
+
+	rec := postWebhook(handler, sign(client.ApiKey, []byte(textPayload)), textPayload)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("ServeHTTP() status = %d; want %d so Kik redelivers", rec.Code, http.StatusInternalServerError)
+	}
+}
+
+func TestWebhookHandler_BadRequests(t *testing.T) {
+	client, _, teardown := kiktest.TestClient(t)
+	defer teardown()
+
+	handler := kik.NewWebhookHandler(client, func(ctx context.Context, m kik.Receive) error { return nil })
+
+	malformed := `{"messages": [`
+	if rec := postWebhook(handler, sign(client.ApiKey, []byte(malformed)), malformed); rec.Code != http.StatusBadRequest {
+		t.Errorf("ServeHTTP(malformed) status = %d; want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/incoming", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("ServeHTTP(GET) status = %d; want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestWebhookHandler_BodyTooLarge(t *testing.T) {
+	client, _, teardown := kiktest.TestClient(t)
+	defer teardown()
+
+	var (
+		gotErr     error
+		dispatched int
+	)
+	handler := kik.NewWebhookHandler(client, func(ctx context.Context, m kik.Receive) error {
+		dispatched++
+		return nil
+	})
+	handler.OnError = func(r *http.Request, err error) { gotErr = err }
+
+	large := strings.Repeat(" ", kik.MaxWebhookBodySize-len(textPayload)+1) + textPayload
+	for _, length := range []int64{int64(len(large)), -1} {
+		gotErr = nil
+		req := httptest.NewRequest("POST", "/incoming", strings.NewReader(large))
+		req.Header.Set(kik.SignatureHeader, sign(client.ApiKey, []byte(large)))
+		req.ContentLength = length
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("ServeHTTP() with content length %d status = %d; want %d", length, rec.Code, http.StatusRequestEntityTooLarge)
+		}
+		if gotErr != kik.BodyTooLargeError {
+			t.Errorf("OnError got %v; want BodyTooLargeError", gotErr)
+		}
+	}
+	if dispatched != 0 {
+		t.Errorf("dispatched %d messages of bodies that are too large; want none", dispatched)
+	}
+
+	fits := large[1:]
+	if rec := postWebhook(handler, sign(client.ApiKey, []byte(fits)), fits); rec.Code != http.StatusOK {
+		t.Errorf("ServeHTTP() with a body of MaxWebhookBodySize status = %d; want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestWebhookHandler_BodyAfterServeHTTP(t *testing.T) {
+	client, _, teardown := kiktest.TestClient(t)
+	defer teardown()
+
+	handler := kik.NewWebhookHandler(client, func(ctx context.Context, m kik.Receive) error { return nil })
+	first := httptest.NewRequest("POST", "/incoming", strings.NewReader(textPayload))
+	first.Header.Set(kik.SignatureHeader, sign(client.ApiKey, []byte(textPayload)))
+	handler.ServeHTTP(httptest.NewRecorder(), first)
+
+	// The next webhook reuses the buffer of the first one.
+	other := `{"messages": [{"chatId": "c2", "id": "3", "type": "text", "from": "bob", "body": "Hello"}]}`
+	postWebhook(handler, sign(client.ApiKey, []byte(other)), other)
+
+	if body, err := ioutil.ReadAll(first.Body); err != nil || len(body) != 0 {
+		t.Errorf("reading the body after ServeHTTP = %q, %v; want it empty", body, err)
+	}
+}
+
+func postWebhook(h http.Handler, signature string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/incoming", strings.NewReader(body))
+	req.Header.Set(kik.SignatureHeader, signature)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+// benchmarkWriter is an http.ResponseWriter discarding the response, so benchmarks measure the handler only.
+type benchmarkWriter struct {
+	header http.Header
+	code   int
+}
+
+func (w *benchmarkWriter) Header() http.Header         { return w.header }
+func (w *benchmarkWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *benchmarkWriter) WriteHeader(code int)        { w.code = code }
+
+type benchmarkBody struct{ *bytes.Reader }
+
+func (benchmarkBody) Close() error { return nil }
+
+// benchmarkWebhook posts the signed textPayload to h from parallel goroutines, reusing a request in each of them.
+func benchmarkWebhook(b *testing.B, client *kik.Client, h http.Handler) {
+	payload := []byte(textPayload)
+	signature := sign(client.ApiKey, payload)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(payload)))
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		req := httptest.NewRequest("POST", "/incoming", nil)
+		req.Header.Set(kik.SignatureHeader, signature)
+		req.ContentLength = int64(len(payload))
+		body := benchmarkBody{bytes.NewReader(nil)}
+		w := &benchmarkWriter{header: make(http.Header)}
+		for pb.Next() {
+			body.Reset(payload)
+			req.Body = body
+			h.ServeHTTP(w, req)
+			if w.code != http.StatusOK {
+				b.Errorf("ServeHTTP() status = %d; want %d", w.code, http.StatusOK)
+				return
+			}
+		}
+	})
+}
+
+func BenchmarkWebhookHandler(b *testing.B) {
+	client, err := kik.NewClient("bot", "key")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	handler := kik.NewWebhookHandler(client, func(ctx context.Context, m kik.Receive) error {
+		return nil
+	})
+	benchmarkWebhook(b, client, handler)
+}
+
+func BenchmarkBot_ServeHTTP(b *testing.B) {
+	client, err := kik.NewClient("bot", "key")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	bot := kik.NewBot(client)
+	bot.HandleText("/start", func(ctx context.Context, m *kik.TextMessageReceive) ([]kik.Message, error) {
+		return reply("started")
+	})
+	bot.HandleText("", func(ctx context.Context, m *kik.TextMessageReceive) ([]kik.Message, error) {
+		return nil, nil
+	})
+	benchmarkWebhook(b, client, bot)
+}
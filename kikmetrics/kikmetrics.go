@@ -0,0 +1,165 @@
+// Package kikmetrics records per endpoint metrics of a kik.Client, without any dependencies.
+//
+// A Collector can be published with expvar, or read with Snapshot to feed Prometheus or OpenTelemetry.
+// To record metrics directly into those, implement the two methods of kik.Metrics instead.
+package kikmetrics
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/r-kells/go-kik/kik"
+)
+
+// EndpointStats are the metrics of a single endpoint.
+type EndpointStats struct {
+	Requests      int64            `json:"requests"`       // Every attempt, including retries.
+	Errors        int64            `json:"errors"`         // Attempts that failed, with or without a response.
+	Retries       int64            `json:"retries"`        // Attempts that were retried.
+	StatusCodes   map[int]int64    `json:"status_codes"`   // Responses by status code.
+	TotalDuration time.Duration    `json:"total_duration"` // The sum of the duration of every attempt.
+	MaxDuration   time.Duration    `json:"max_duration"`
+	Buckets       map[string]int64 `json:"buckets"`      // Attempts by duration, see Buckets.
+	CacheHits     int64            `json:"cache_hits"`   // Lookups answered by a cache, without a request.
+	CacheMisses   int64            `json:"cache_misses"` // Lookups that were not cached and needed a request.
+	Throttled     int64            `json:"throttled"`    // Incoming messages a kik.Throttle kept from their handler, see kik.EndpointWebhook.
+}
+
+// MeanDuration returns the average duration of an attempt.
+func (s EndpointStats) MeanDuration() time.Duration {
+	if s.Requests == 0 {
+		return 0
+	}
+	return s.TotalDuration / time.Duration(s.Requests)
+}
+
+// HitRate returns the share of cache lookups that were hits, 0 if the endpoint is not cached.
+func (s EndpointStats) HitRate() float64 {
+	lookups := s.CacheHits + s.CacheMisses
+	if lookups == 0 {
+		return 0
+	}
+	return float64(s.CacheHits) / float64(lookups)
+}
+
+// Buckets are the upper bounds of the latency histogram of each endpoint.
+var Buckets = []time.Duration{
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+}
+
+// Collector is a kik.Metrics keeping counts and latencies in memory.
+// It implements expvar.Var, so it can be published with expvar.Publish("kik", collector).
+type Collector struct {
+	mu        sync.Mutex
+	endpoints map[string]*EndpointStats
+}
+
+var (
+	_ kik.Metrics         = (*Collector)(nil)
+	_ kik.CacheMetrics    = (*Collector)(nil)
+	_ kik.ThrottleMetrics = (*Collector)(nil)
+)
+
+// NewCollector returns an empty Collector.
+func NewCollector() *Collector {
+	return &Collector{endpoints: make(map[string]*EndpointStats)}
+}
+
+func (c *Collector) ObserveRequest(endpoint string, statusCode int, duration time.Duration, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s := c.stats(endpoint)
+	s.Requests++
+	if err != nil {
+		s.Errors++
+	}
+	if statusCode != 0 {
+		s.StatusCodes[statusCode]++
+	}
+	s.TotalDuration += duration
+	if duration > s.MaxDuration {
+		s.MaxDuration = duration
+	}
+	s.Buckets[bucket(duration)]++
+}
+
+func (c *Collector) ObserveRetry(endpoint string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats(endpoint).Retries++
+}
+
+func (c *Collector) ObserveCache(endpoint string, hit bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if hit {
+		c.stats(endpoint).CacheHits++
+	} else {
+		c.stats(endpoint).CacheMisses++
+	}
+}
+
+// ObserveThrottle counts the throttled message as Throttled of kik.EndpointWebhook.
+func (c *Collector) ObserveThrottle(messageType string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats(kik.EndpointWebhook).Throttled++
+}
+
+// Snapshot returns a copy of the metrics of every endpoint that was called.
+func (c *Collector) Snapshot() map[string]EndpointStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	snapshot := make(map[string]EndpointStats, len(c.endpoints))
+	for name, s := range c.endpoints {
+		cp := *s
+		cp.StatusCodes = make(map[int]int64, len(s.StatusCodes))
+		for code, n := range s.StatusCodes {
+			cp.StatusCodes[code] = n
+		}
+		cp.Buckets = make(map[string]int64, len(s.Buckets))
+		for b, n := range s.Buckets {
+			cp.Buckets[b] = n
+		}
+		snapshot[name] = cp
+	}
+	return snapshot
+}
+
+// String returns the Snapshot as JSON, implementing expvar.Var.
+func (c *Collector) String() string {
+	b, _ := json.Marshal(c.Snapshot())
+	return string(b)
+}
+
+// stats returns the stats of endpoint, c.mu must be held.
+func (c *Collector) stats(endpoint string) *EndpointStats {
+	s, ok := c.endpoints[endpoint]
+	if !ok {
+		s = &EndpointStats{
+			StatusCodes: make(map[int]int64),
+			Buckets:     make(map[string]int64),
+		}
+		c.endpoints[endpoint] = s
+	}
+	return s
+}
+
+// bucket returns the name of the smallest bucket duration fits in.
+func bucket(duration time.Duration) string {
+	for _, b := range Buckets {
+		if duration <= b {
+			return "le_" + b.String()
+		}
+	}
+	return "le_inf"
+}
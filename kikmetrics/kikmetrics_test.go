@@ -0,0 +1,89 @@
+package kikmetrics_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/r-kells/go-kik/kik"
+	"github.com/r-kells/go-kik/kikmetrics"
+	"github.com/r-kells/go-kik/kiktest"
+)
+
+func TestCollector_RecordsEndpoints(t *testing.T) {
+	client, mux, teardown := kiktest.TestClient(t)
+	defer teardown()
+
+	collector := kikmetrics.NewCollector()
+	client.Metrics = collector
+	client.RetryPolicy = kik.DefaultRetryPolicy()
+	client.RetryPolicy.Backoff = time.Millisecond
+
+	attempts := 0
+	mux.HandleFunc(kik.SendMessageUrl, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	})
+	mux.HandleFunc(kik.GetUserUrl, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{}`)
+	})
+
+	client.SendMessage(context.Background(), []kik.Message{
+		kik.TextMessage{SendMessage: kik.SendMessage{To: "kikteam", Type: "text"}, Body: "Hi"},
+	})
+	client.GetUser(context.Background(), "kikteam")
+	client.GetUser(context.Background(), "kikteam")
+
+	snapshot := collector.Snapshot()
+
+	send := snapshot[kik.EndpointSend]
+	if send.Requests != 2 || send.Errors != 1 || send.Retries != 1 {
+		t.Errorf("send stats = %+v; want 2 requests, 1 error and 1 retry", send)
+	}
+	if send.StatusCodes[503] != 1 || send.StatusCodes[200] != 1 {
+		t.Errorf("send status codes = %v; want one 503 and one 200", send.StatusCodes)
+	}
+	user := snapshot[kik.EndpointUser]
+	if user.Requests != 2 || user.Errors != 0 || user.MeanDuration() <= 0 {
+		t.Errorf("user stats = %+v; want 2 successful requests", user)
+	}
+}
+
+func TestCollector_String(t *testing.T) {
+	collector := kikmetrics.NewCollector()
+	collector.ObserveRequest(kik.EndpointCode, 0, 3*time.Second, context.DeadlineExceeded)
+
+	var got map[string]kikmetrics.EndpointStats
+	if err := json.Unmarshal([]byte(collector.String()), &got); err != nil {
+		t.Fatalf("String() is not valid JSON: %v", err)
+	}
+	if got[kik.EndpointCode].Errors != 1 || got[kik.EndpointCode].Buckets["le_5s"] != 1 {
+		t.Errorf("String() = %s; want one failed code request in the 5s bucket", collector.String())
+	}
+}
+
+func TestCollector_CacheHitRate(t *testing.T) {
+	client, mux, teardown := kiktest.TestClient(t)
+	defer teardown()
+
+	mux.HandleFunc(kik.GetUserUrl, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{}`)
+	})
+	collector := kikmetrics.NewCollector()
+	client.Metrics = collector
+	client.UserCache = kik.NewMemoryUserCache(0)
+
+	for i := 0; i < 4; i++ {
+		client.GetUser(context.Background(), "kikteam")
+	}
+
+	user := collector.Snapshot()[kik.EndpointUser]
+	if user.Requests != 1 || user.CacheHits != 3 || user.CacheMisses != 1 || user.HitRate() != 0.75 {
+		t.Errorf("user stats = %+v; want 1 request, 3 hits and 1 miss", user)
+	}
+}
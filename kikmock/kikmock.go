@@ -0,0 +1,288 @@
+// Package kikmock provides a mock of kik.API for unit tests of code using a Kik client.
+//
+// Every method of API records its call and calls the function field of the same name, if set.
+// Methods without a function succeed: messages are sent, lookups return empty values and signatures are valid.
+//
+//	api := &kikmock.API{
+//		GetUserFunc: func(ctx context.Context, username string) (*kik.User, error) {
+//			return &kik.User{FirstName: "Laura"}, nil
+//		},
+//	}
+//	greet(ctx, api, "laura")
+//	if calls := api.CallsTo("SendMessage"); len(calls) != 1 { ... }
+//
+// To test against HTTP instead, e.g. retries or webhooks, use the kiktest package.
+package kikmock
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/r-kells/go-kik/kik"
+)
+
+// Call is a recorded call of an API method.
+type Call struct {
+	Method string
+	Args   []interface{} // The arguments after the context, in order.
+}
+
+// API is a kik.API recording its calls, it is safe for concurrent use.
+type API struct {
+	SendMessageFunc      func(ctx context.Context, messages []kik.Message) error
+	SendIsTypingFunc     func(ctx context.Context, to string, chatId string, typing bool) error
+	SendReadReceiptFunc  func(ctx context.Context, to string, chatId string, messageIds []string) error
+	SendVideoFileFunc    func(ctx context.Context, path string, upload kik.Uploader, message kik.VideoMessage) error
+	ReplyFunc            func(ctx context.Context, incoming kik.Receive, messages ...kik.Message) error
+	BroadcastMessageFunc func(ctx context.Context, messages []kik.Message) error
+	BroadcastToUsersFunc func(ctx context.Context, usernames []string, template kik.Message) ([]kik.BroadcastResult, error)
+	EstimateSizeFunc     func(messages []kik.Message) (int, error)
+
+	GetUserFunc                func(ctx context.Context, username string) (*kik.User, error)
+	GetUsersFunc               func(ctx context.Context, usernames []string, concurrency int) (map[string]*kik.User, map[string]error)
+	FetchProfilePicFunc        func(ctx context.Context, user *kik.User, ifModifiedSince time.Time) ([]byte, bool, error)
+	DownloadProfilePictureFunc func(ctx context.Context, user *kik.User, cached *kik.ProfilePicture) (*kik.ProfilePicture, error)
+
+	GetConfigurationFunc func(ctx context.Context) (*kik.Configuration, error)
+	SetConfigurationFunc func(ctx context.Context, c *kik.Configuration) error
+
+	CreateCodeFunc   func(ctx context.Context, s *kik.ScanData) (*kik.Code, error)
+	DownloadCodeFunc func(ctx context.Context, code *kik.Code, color kik.CodeColor) ([]byte, error)
+
+	VerifySignatureFunc func(signature string, body []byte) bool
+	VerifyRequestFunc   func(r *http.Request) ([]byte, error)
+	BatchVerifyFunc     func(payloads []kik.SignedPayload) []bool
+
+	DoFunc func(ctx context.Context, method, path string, body, out interface{}) error
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ kik.API = (*API)(nil)
+
+// Calls returns every recorded call, in order.
+func (m *API) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// CallsTo returns the recorded calls of method, e.g. "SendMessage", in order.
+func (m *API) CallsTo(method string) []Call {
+	var calls []Call
+	for _, c := range m.Calls() {
+		if c.Method == method {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// SentMessages returns the messages of every SendMessage and Reply call, in order.
+func (m *API) SentMessages() []kik.Message {
+	var messages []kik.Message
+	for _, c := range m.Calls() {
+		switch c.Method {
+		case "SendMessage":
+			messages = append(messages, c.Args[0].([]kik.Message)...)
+		case "Reply":
+			messages = append(messages, c.Args[1].([]kik.Message)...)
+		}
+	}
+	return messages
+}
+
+// Reset forgets all recorded calls.
+func (m *API) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = nil
+}
+
+func (m *API) record(method string, args ...interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+}
+
+func (m *API) SendMessage(ctx context.Context, messages []kik.Message) error {
+	m.record("SendMessage", messages)
+	if m.SendMessageFunc != nil {
+		return m.SendMessageFunc(ctx, messages)
+	}
+	return nil
+}
+
+func (m *API) SendIsTyping(ctx context.Context, to string, chatId string, typing bool) error {
+	m.record("SendIsTyping", to, chatId, typing)
+	if m.SendIsTypingFunc != nil {
+		return m.SendIsTypingFunc(ctx, to, chatId, typing)
+	}
+	return nil
+}
+
+func (m *API) SendReadReceipt(ctx context.Context, to string, chatId string, messageIds []string) error {
+	m.record("SendReadReceipt", to, chatId, messageIds)
+	if m.SendReadReceiptFunc != nil {
+		return m.SendReadReceiptFunc(ctx, to, chatId, messageIds)
+	}
+	return nil
+}
+
+func (m *API) SendVideoFile(ctx context.Context, path string, upload kik.Uploader, message kik.VideoMessage) error {
+	m.record("SendVideoFile", path, upload, message)
+	if m.SendVideoFileFunc != nil {
+		return m.SendVideoFileFunc(ctx, path, upload, message)
+	}
+	return nil
+}
+
+func (m *API) Reply(ctx context.Context, incoming kik.Receive, messages ...kik.Message) error {
+	m.record("Reply", incoming, messages)
+	if m.ReplyFunc != nil {
+		return m.ReplyFunc(ctx, incoming, messages...)
+	}
+	return nil
+}
+
+func (m *API) BroadcastMessage(ctx context.Context, messages []kik.Message) error {
+	m.record("BroadcastMessage", messages)
+	if m.BroadcastMessageFunc != nil {
+		return m.BroadcastMessageFunc(ctx, messages)
+	}
+	return nil
+}
+
+func (m *API) BroadcastToUsers(ctx context.Context, usernames []string, template kik.Message) ([]kik.BroadcastResult, error) {
+	m.record("BroadcastToUsers", usernames, template)
+	if m.BroadcastToUsersFunc != nil {
+		return m.BroadcastToUsersFunc(ctx, usernames, template)
+	}
+	results := make([]kik.BroadcastResult, len(usernames))
+	for i, username := range usernames {
+		results[i].Username = username
+	}
+	return results, nil
+}
+
+func (m *API) EstimateSize(messages []kik.Message) (int, error) {
+	m.record("EstimateSize", messages)
+	if m.EstimateSizeFunc != nil {
+		return m.EstimateSizeFunc(messages)
+	}
+	return 0, nil
+}
+
+func (m *API) GetUser(ctx context.Context, username string) (*kik.User, error) {
+	m.record("GetUser", username)
+	if m.GetUserFunc != nil {
+		return m.GetUserFunc(ctx, username)
+	}
+	return &kik.User{}, nil
+}
+
+func (m *API) GetUsers(ctx context.Context, usernames []string, concurrency int) (map[string]*kik.User, map[string]error) {
+	m.record("GetUsers", usernames, concurrency)
+	if m.GetUsersFunc != nil {
+		return m.GetUsersFunc(ctx, usernames, concurrency)
+	}
+	users := make(map[string]*kik.User, len(usernames))
+	for _, username := range usernames {
+		users[username] = &kik.User{}
+	}
+	return users, nil
+}
+
+func (m *API) FetchProfilePic(ctx context.Context, user *kik.User, ifModifiedSince time.Time) ([]byte, bool, error) {
+	m.record("FetchProfilePic", user, ifModifiedSince)
+	if m.FetchProfilePicFunc != nil {
+		return m.FetchProfilePicFunc(ctx, user, ifModifiedSince)
+	}
+	return nil, false, nil
+}
+
+func (m *API) DownloadProfilePicture(ctx context.Context, user *kik.User, cached *kik.ProfilePicture) (*kik.ProfilePicture, error) {
+	m.record("DownloadProfilePicture", user, cached)
+	if m.DownloadProfilePictureFunc != nil {
+		return m.DownloadProfilePictureFunc(ctx, user, cached)
+	}
+	return &kik.ProfilePicture{}, nil
+}
+
+func (m *API) GetConfiguration(ctx context.Context) (*kik.Configuration, error) {
+	m.record("GetConfiguration")
+	if m.GetConfigurationFunc != nil {
+		return m.GetConfigurationFunc(ctx)
+	}
+	return &kik.Configuration{Features: &kik.Features{}}, nil
+}
+
+func (m *API) SetConfiguration(ctx context.Context, c *kik.Configuration) error {
+	m.record("SetConfiguration", c)
+	if m.SetConfigurationFunc != nil {
+		return m.SetConfigurationFunc(ctx, c)
+	}
+	return nil
+}
+
+func (m *API) CreateCode(ctx context.Context, s *kik.ScanData) (*kik.Code, error) {
+	m.record("CreateCode", s)
+	if m.CreateCodeFunc != nil {
+		return m.CreateCodeFunc(ctx, s)
+	}
+	return &kik.Code{}, nil
+}
+
+func (m *API) DownloadCode(ctx context.Context, code *kik.Code, color kik.CodeColor) ([]byte, error) {
+	m.record("DownloadCode", code, color)
+	if m.DownloadCodeFunc != nil {
+		return m.DownloadCodeFunc(ctx, code, color)
+	}
+	return nil, nil
+}
+
+func (m *API) VerifySignature(signature string, body []byte) bool {
+	m.record("VerifySignature", signature, body)
+	if m.VerifySignatureFunc != nil {
+		return m.VerifySignatureFunc(signature, body)
+	}
+	return true
+}
+
+func (m *API) VerifyRequest(r *http.Request) ([]byte, error) {
+	m.record("VerifyRequest", r)
+	if m.VerifyRequestFunc != nil {
+		return m.VerifyRequestFunc(r)
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+func (m *API) BatchVerify(payloads []kik.SignedPayload) []bool {
+	m.record("BatchVerify", payloads)
+	if m.BatchVerifyFunc != nil {
+		return m.BatchVerifyFunc(payloads)
+	}
+	valid := make([]bool, len(payloads))
+	for i := range valid {
+		valid[i] = true
+	}
+	return valid
+}
+
+func (m *API) Do(ctx context.Context, method, path string, body, out interface{}) error {
+	m.record("Do", method, path, body, out)
+	if m.DoFunc != nil {
+		return m.DoFunc(ctx, method, path, body, out)
+	}
+	return nil
+}
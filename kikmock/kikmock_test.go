@@ -0,0 +1,61 @@
+package kikmock_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/r-kells/go-kik/kik"
+	"github.com/r-kells/go-kik/kikmock"
+)
+
+// greet is business logic under test, it only depends on kik.API.
+func greet(ctx context.Context, api kik.API, username string) error {
+	user, err := api.GetUser(ctx, username)
+	if err != nil {
+		return err
+	}
+	return api.SendMessage(ctx, []kik.Message{
+		kik.TextMessage{SendMessage: kik.SendMessage{To: username, Type: "text"}, Body: "Hi " + user.FirstName},
+	})
+}
+
+func TestAPI_RecordsCalls(t *testing.T) {
+	api := &kikmock.API{
+		GetUserFunc: func(ctx context.Context, username string) (*kik.User, error) {
+			return &kik.User{FirstName: "Laura"}, nil
+		},
+	}
+
+	if err := greet(context.Background(), api, "laura"); err != nil {
+		t.Fatalf("greet() returned an error = %+v; expected no error", err)
+	}
+
+	calls := api.Calls()
+	if len(calls) != 2 || calls[0].Method != "GetUser" || calls[0].Args[0] != "laura" || calls[1].Method != "SendMessage" {
+		t.Errorf("Calls() = %+v; want GetUser(laura) then SendMessage", calls)
+	}
+	sent := api.SentMessages()
+	if len(sent) != 1 || sent[0].(kik.TextMessage).Body != "Hi Laura" {
+		t.Errorf("SentMessages() = %+v; want the greeting", sent)
+	}
+
+	api.Reset()
+	if calls := api.Calls(); len(calls) != 0 {
+		t.Errorf("Calls() after Reset = %+v; want none", calls)
+	}
+}
+
+func TestAPI_Errors(t *testing.T) {
+	wantErr := errors.New("user not found")
+	api := &kikmock.API{
+		GetUserFunc: func(ctx context.Context, username string) (*kik.User, error) { return nil, wantErr },
+	}
+
+	if err := greet(context.Background(), api, "laura"); err != wantErr {
+		t.Errorf("greet() = %v; want %v", err, wantErr)
+	}
+	if calls := api.CallsTo("SendMessage"); len(calls) != 0 {
+		t.Errorf("CallsTo(SendMessage) = %+v; want no message sent", calls)
+	}
+}
@@ -0,0 +1,171 @@
+package kiktest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/r-kells/go-kik/kik"
+)
+
+// UpdateGoldenEnv is the environment variable that makes AssertGolden write the golden files instead of comparing them:
+//
+//	KIKTEST_UPDATE=1 go test ./...
+const UpdateGoldenEnv = "KIKTEST_UPDATE"
+
+// Turn is a webhook sent to the bot and the messages it sent in response, as recorded by a Conversation.
+type Turn struct {
+	In  []interface{} `json:"in"`
+	Out []interface{} `json:"out"`
+}
+
+// Conversation scripts a conversation with a bot, e.g. a *kik.Bot, through a Server, and records it
+// as a transcript of Turns to compare with a golden file:
+//
+//	conv := kiktest.NewConversation(t, server, bot)
+//	conv.Text("laura", "Hi")
+//	conv.Text("laura", "/start")
+//	conv.AssertGolden("testdata/start.golden.json")
+//
+// Messages are recorded as JSON without their zero fields, so adding a field to a message type does not change the golden files.
+type Conversation struct {
+	Server *Server
+	Bot    http.Handler
+	ChatId string // The chat of the messages sent by Text, defaults to "chat".
+
+	t     testing.TB
+	turns []Turn
+}
+
+// NewConversation returns a Conversation with bot, which sends its messages to server.
+func NewConversation(t testing.TB, server *Server, bot http.Handler) *Conversation {
+	return &Conversation{Server: server, Bot: bot, ChatId: "chat", t: t}
+}
+
+// Send sends messages to the bot in a single webhook, like Server.Webhook, and returns the messages it sent in response.
+// The test fails if the bot does not respond with 200 OK.
+func (c *Conversation) Send(messages ...interface{}) []SentMessage {
+	c.t.Helper()
+	before := len(c.Server.Messages())
+	if rec := c.Server.Webhook(c.Bot, messages...); rec.Code != http.StatusOK {
+		c.t.Errorf("webhook %d returned %d: %s", len(c.turns), rec.Code, rec.Body.String())
+	}
+	sent := c.Server.Messages()
+	if len(sent) < before {
+		before = 0 // The Server was Reset by the bot or the test.
+	}
+	sent = sent[before:]
+
+	turn := Turn{In: make([]interface{}, len(messages)), Out: make([]interface{}, len(sent))}
+	for i, m := range messages {
+		turn.In[i] = c.normalize(m)
+	}
+	for i, m := range sent {
+		turn.Out[i] = c.normalize(m.Raw)
+	}
+	c.turns = append(c.turns, turn)
+	return sent
+}
+
+// Text sends a text message with body from a user in the ChatId of the Conversation, see Send.
+func (c *Conversation) Text(from, body string) []SentMessage {
+	c.t.Helper()
+	return c.Send(&kik.TextMessageReceive{
+		ReceiveMessage: kik.ReceiveMessage{Type: "text", From: from, ChatId: c.ChatId},
+		Body:           body,
+	})
+}
+
+// Turns returns the recorded transcript.
+func (c *Conversation) Turns() []Turn {
+	return append([]Turn(nil), c.turns...)
+}
+
+// AssertGolden fails the test if the transcript differs from the golden file at path.
+// With UpdateGoldenEnv set, the file is written with the transcript instead.
+func (c *Conversation) AssertGolden(path string) {
+	c.t.Helper()
+	got, err := json.MarshalIndent(c.turns, "", "  ")
+	if err != nil {
+		c.t.Fatalf("error encoding the conversation: %v", err)
+	}
+	got = append(got, '\n')
+
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			c.t.Fatalf("error creating the golden file: %v", err)
+		}
+		if err := ioutil.WriteFile(path, got, 0644); err != nil {
+			c.t.Fatalf("error writing the golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		c.t.Fatalf("error reading the golden file, run the test with %s=1 to create it: %v", UpdateGoldenEnv, err)
+	}
+	if !bytes.Equal(bytes.TrimSpace(got), bytes.TrimSpace(want)) {
+		c.t.Errorf("conversation does not match %s, run the test with %s=1 to update it.\ngot:\n%s\nwant:\n%s",
+			path, UpdateGoldenEnv, got, want)
+	}
+}
+
+// normalize returns m as generic JSON without zero fields.
+func (c *Conversation) normalize(m interface{}) interface{} {
+	c.t.Helper()
+	raw, ok := m.(json.RawMessage)
+	if !ok {
+		var err error
+		if raw, err = json.Marshal(m); err != nil {
+			c.t.Fatalf("error encoding %T: %v", m, err)
+		}
+	}
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		c.t.Fatalf("error decoding %s: %v", raw, err)
+	}
+	return dropZero(v)
+}
+
+// dropZero removes the fields of objects that are null, false, 0, "" or empty, recursively.
+func dropZero(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			field = dropZero(field)
+			if isZero(field) {
+				delete(v, key)
+			} else {
+				v[key] = field
+			}
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = dropZero(e)
+		}
+	}
+	return v
+}
+
+func isZero(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case bool:
+		return !v
+	case float64:
+		return v == 0
+	case string:
+		return v == ""
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package kiktest_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/r-kells/go-kik/kik"
+	"github.com/r-kells/go-kik/kiktest"
+)
+
+func TestConversation_Golden(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+
+	bot := kik.NewBot(server.Client)
+	bot.HandleText("/start", func(ctx context.Context, m *kik.TextMessageReceive) ([]kik.Message, error) {
+		keyboard := kik.NewKeyboard().WithTextResponses("Yes", "No").Build()
+		return []kik.Message{kik.TextMessage{
+			SendMessage: kik.SendMessage{Type: "text", Keyboards: []kik.SuggestedResponseKeyboard{keyboard}},
+			Body:        "Welcome! Ready?",
+		}}, nil
+	})
+	bot.HandleText("", func(ctx context.Context, m *kik.TextMessageReceive) ([]kik.Message, error) {
+		return nil, nil
+	})
+
+	conv := kiktest.NewConversation(t, server, bot)
+	if sent := conv.Text("laura", "/start"); len(sent) != 1 || sent[0].To != "laura" || sent[0].ChatId != "chat" {
+		t.Errorf("Text(/start) = %+v; want the welcome to laura", sent)
+	}
+	if sent := conv.Text("laura", "Yes"); len(sent) != 0 {
+		t.Errorf("Text(Yes) = %+v; want no reply", sent)
+	}
+	conv.AssertGolden("testdata/start.golden.json")
+}
@@ -0,0 +1,296 @@
+package kiktest
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/r-kells/go-kik/kik"
+)
+
+// SentMessage is a message the bot sent to the Server.
+type SentMessage struct {
+	Endpoint string // kik.SendMessageUrl or kik.BroadcastUrl.
+	To       string
+	ChatId   string
+	Type     string
+	Body     string          // Only set for text messages.
+	Raw      json.RawMessage // The full message as sent.
+}
+
+// Decode unmarshals the full message into v, e.g. a *kik.LinkMessage.
+func (m SentMessage) Decode(v interface{}) error {
+	return json.Unmarshal(m.Raw, v)
+}
+
+// Server is an in-memory fake of the Kik API for end-to-end tests of bots.
+// It records sent messages, serves canned users and the configuration, creates codes,
+// can simulate errors and rate limits, and can send signed webhooks to the bot.
+type Server struct {
+	*httptest.Server
+	Client *kik.Client // A Client authenticated against the Server.
+
+	BotUsername string
+	ApiKey      string
+
+	mu       sync.Mutex
+	sent     []SentMessage
+	users    map[string]*kik.User
+	config   *kik.Configuration
+	codes    map[string]string // code id -> data.
+	failures map[string][]failure
+}
+
+type failure struct {
+	statusCode int
+	retryAfter string
+}
+
+// NewServer starts a Server, call Close when done with it.
+func NewServer(t testing.TB) *Server {
+	s := &Server{
+		BotUsername: "test",
+		ApiKey:      "test",
+		users:       make(map[string]*kik.User),
+		codes:       make(map[string]string),
+		failures:    make(map[string][]failure),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(kik.SendMessageUrl, s.handleMessages)
+	mux.HandleFunc(kik.BroadcastUrl, s.handleMessages)
+	mux.HandleFunc(kik.GetUserUrl, s.handleUser)
+	mux.HandleFunc(kik.ConfigtUrl, s.handleConfig)
+	mux.HandleFunc(kik.CodeUrl, s.handleCode)
+	s.Server = httptest.NewServer(s.authenticate(mux))
+
+	c, err := kik.NewKikClient(s.URL+"/", s.BotUsername, s.ApiKey, s.Server.Client())
+	if err != nil {
+		s.Close()
+		t.Fatalf("error starting the kiktest server: %s", err)
+	}
+	s.Client = c
+	return s
+}
+
+// AddUser makes GetUser return user for username, unknown users return a 404.
+func (s *Server) AddUser(username string, user *kik.User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[username] = user
+}
+
+// SetConfiguration sets the configuration returned by GetConfiguration.
+func (s *Server) SetConfiguration(config *kik.Configuration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.config = config
+}
+
+// Configuration returns the configuration last set by the bot, or nil.
+func (s *Server) Configuration() *kik.Configuration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.config
+}
+
+// CodeData returns the data embedded in a code created by the bot.
+func (s *Server) CodeData(id string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.codes[id]
+	return data, ok
+}
+
+// Messages returns every message sent or broadcast to the Server, in order.
+func (s *Server) Messages() []SentMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]SentMessage(nil), s.sent...)
+}
+
+// Reset forgets all sent messages.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = nil
+}
+
+// Fail makes the next times requests to path respond with statusCode and a Kik error body.
+func (s *Server) Fail(path string, statusCode int, times int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 0; i < times; i++ {
+		s.failures[path] = append(s.failures[path], failure{statusCode: statusCode})
+	}
+}
+
+// RateLimit makes the next times requests to path respond with 429 Too Many Requests,
+// asking to retry after retryAfterSeconds.
+func (s *Server) RateLimit(path string, times int, retryAfterSeconds int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 0; i < times; i++ {
+		s.failures[path] = append(s.failures[path], failure{
+			statusCode: http.StatusTooManyRequests,
+			retryAfter: fmt.Sprint(retryAfterSeconds),
+		})
+	}
+}
+
+// Sign returns the signature Kik sends along with body.
+func (s *Server) Sign(body []byte) string {
+	h := hmac.New(sha1.New, []byte(s.ApiKey))
+	h.Write(body)
+	return strings.ToUpper(hex.EncodeToString(h.Sum(nil)))
+}
+
+// Webhook sends messages to a webhook handler the way Kik does, signed with the ApiKey.
+// Messages are usually *Receive types, their Type must be set.
+func (s *Server) Webhook(h http.Handler, messages ...interface{}) *httptest.ResponseRecorder {
+	body, err := json.Marshal(map[string]interface{}{"messages": messages})
+	if err != nil {
+		panic(err)
+	}
+	req := httptest.NewRequest("POST", "/", bytes.NewReader(body))
+	req.Header.Set(kik.SignatureHeader, s.Sign(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+// WebhookTo sends messages to a running bot at url, like Webhook.
+func (s *Server) WebhookTo(url string, messages ...interface{}) (*http.Response, error) {
+	body, err := json.Marshal(map[string]interface{}{"messages": messages})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(kik.SignatureHeader, s.Sign(body))
+	req.Header.Set("Content-Type", "application/json")
+	return http.DefaultClient.Do(req)
+}
+
+// authenticate rejects requests without the bot's credentials and serves simulated failures.
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, key, ok := r.BasicAuth()
+		if !ok || username != s.BotUsername || key != s.ApiKey {
+			writeError(w, http.StatusUnauthorized, "Unauthorized", "Invalid credentials")
+			return
+		}
+
+		path := r.URL.Path
+		if strings.HasPrefix(path, kik.GetUserUrl) {
+			path = kik.GetUserUrl
+		}
+		s.mu.Lock()
+		var f *failure
+		if queued := s.failures[path]; len(queued) > 0 {
+			f = &queued[0]
+			s.failures[path] = queued[1:]
+		}
+		s.mu.Unlock()
+
+		if f != nil {
+			if f.retryAfter != "" {
+				w.Header().Set("Retry-After", f.retryAfter)
+			}
+			writeError(w, f.statusCode, http.StatusText(f.statusCode), "Simulated failure")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleMessages(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Messages []json.RawMessage `json:"messages"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || len(payload.Messages) == 0 {
+		writeError(w, http.StatusBadRequest, "BadRequest", "Invalid messages")
+		return
+	}
+
+	var sent []SentMessage
+	for _, raw := range payload.Messages {
+		m := SentMessage{Endpoint: r.URL.Path, Raw: raw}
+		var fields struct {
+			To, ChatId, Type, Body string
+		}
+		if err := json.Unmarshal(raw, &fields); err != nil || fields.To == "" || fields.Type == "" {
+			writeError(w, http.StatusBadRequest, "BadRequest", "Messages require a to and type")
+			return
+		}
+		m.To, m.ChatId, m.Type, m.Body = fields.To, fields.ChatId, fields.Type, fields.Body
+		sent = append(sent, m)
+	}
+
+	s.mu.Lock()
+	s.sent = append(s.sent, sent...)
+	s.mu.Unlock()
+	fmt.Fprint(w, "{}")
+}
+
+func (s *Server) handleUser(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	user, ok := s.users[strings.TrimPrefix(r.URL.Path, kik.GetUserUrl)]
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, "NotFound", "User not found")
+		return
+	}
+	json.NewEncoder(w).Encode(user)
+}
+
+func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "POST" {
+		var config kik.Configuration
+		if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+			writeError(w, http.StatusBadRequest, "BadRequest", err.Error())
+			return
+		}
+		s.SetConfiguration(&config)
+	}
+
+	config := s.Configuration()
+	if config == nil {
+		writeError(w, http.StatusNotFound, "NotFound", "No configuration set")
+		return
+	}
+	json.NewEncoder(w).Encode(config)
+}
+
+func (s *Server) handleCode(w http.ResponseWriter, r *http.Request) {
+	var scanData kik.ScanData
+	if err := json.NewDecoder(r.Body).Decode(&scanData); err != nil {
+		writeError(w, http.StatusBadRequest, "BadRequest", err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	id := fmt.Sprintf("%032x", len(s.codes)+1)
+	s.codes[id] = scanData.Data
+	s.mu.Unlock()
+	json.NewEncoder(w).Encode(kik.Code{Id: id})
+}
+
+func writeError(w http.ResponseWriter, statusCode int, code string, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(map[string]string{"error": code, "message": message})
+}
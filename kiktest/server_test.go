@@ -0,0 +1,149 @@
+package kiktest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/r-kells/go-kik/kik"
+	"github.com/r-kells/go-kik/kiktest"
+)
+
+func TestServer_RecordsMessages(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+
+	err := server.Client.SendMessage(context.Background(), []kik.Message{
+		kik.TextMessage{SendMessage: kik.SendMessage{To: "laura", Type: "text", ChatId: "c"}, Body: "Hi"},
+		kik.LinkMessage{SendMessage: kik.SendMessage{To: "laura", Type: "link"}, Url: "https://duckduckgo.com/"},
+	})
+	if err != nil {
+		t.Fatalf("SendMessage() returned an error = %+v; expected no error", err)
+	}
+
+	sent := server.Messages()
+	if len(sent) != 2 || sent[0].Body != "Hi" || sent[0].ChatId != "c" || sent[0].Endpoint != kik.SendMessageUrl {
+		t.Fatalf("Messages() = %+v; want the text and link message", sent)
+	}
+	var link kik.LinkMessage
+	if err := sent[1].Decode(&link); err != nil || link.Url != "https://duckduckgo.com/" {
+		t.Errorf("Decode() = %+v, %v; want the link message", link, err)
+	}
+
+	server.Reset()
+	if len(server.Messages()) != 0 {
+		t.Errorf("Messages() after Reset() = %v; want none", server.Messages())
+	}
+}
+
+func TestServer_Users(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+
+	want := &kik.User{FirstName: "Laura"}
+	server.AddUser("laura", want)
+
+	got, err := server.Client.GetUser(context.Background(), "laura")
+	if err != nil || !cmp.Equal(got, want) {
+		t.Errorf("GetUser(laura) = %v, %v; want %v", got, err, want)
+	}
+
+	_, err = server.Client.GetUser(context.Background(), "nobody")
+	var apiErr *kik.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("GetUser(nobody) = %v; want a 404 *kik.APIError", err)
+	}
+}
+
+func TestServer_ConfigurationAndCodes(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+	ctx := context.Background()
+
+	config := &kik.Configuration{Webhook: "https://example.com/incoming", Features: &kik.Features{ReceiveIsTyping: true}}
+	if err := server.Client.SetConfiguration(ctx, config); err != nil {
+		t.Fatalf("SetConfiguration() returned an error = %+v; expected no error", err)
+	}
+	got, err := server.Client.GetConfiguration(ctx)
+	if err != nil || !cmp.Equal(got, config) {
+		t.Errorf("GetConfiguration() = %v, %v; want %v", got, err, config)
+	}
+
+	code, err := server.Client.CreateCode(ctx, &kik.ScanData{Data: "campaign"})
+	if err != nil {
+		t.Fatalf("CreateCode() returned an error = %+v; expected no error", err)
+	}
+	if data, ok := server.CodeData(code.Id); !ok || data != "campaign" {
+		t.Errorf("CodeData(%s) = %s; want campaign", code.Id, data)
+	}
+}
+
+func TestServer_Failures(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+	server.AddUser("laura", &kik.User{})
+
+	server.Fail(kik.GetUserUrl, http.StatusInternalServerError, 1)
+	server.RateLimit(kik.GetUserUrl, 1, 0)
+
+	for _, want := range []int{http.StatusInternalServerError, http.StatusTooManyRequests} {
+		_, err := server.Client.GetUser(context.Background(), "laura")
+		var apiErr *kik.APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != want {
+			t.Errorf("GetUser(laura) = %v; want a %d *kik.APIError", err, want)
+		}
+	}
+	if _, err := server.Client.GetUser(context.Background(), "laura"); err != nil {
+		t.Errorf("GetUser(laura) returned an error = %+v after the simulated failures", err)
+	}
+
+	// Retried requests succeed once the failures are used up.
+	server.RateLimit(kik.SendMessageUrl, 2, 0)
+	server.Client.RetryPolicy = kik.DefaultRetryPolicy()
+	server.Client.RetryPolicy.Backoff = time.Millisecond
+	err := server.Client.SendMessage(context.Background(), []kik.Message{
+		kik.TextMessage{SendMessage: kik.SendMessage{To: "laura", Type: "text"}, Body: "Hi"},
+	})
+	if err != nil || len(server.Messages()) != 1 {
+		t.Errorf("SendMessage() = %v; want the message to be sent after retrying", err)
+	}
+}
+
+func TestServer_Unauthorized(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+	server.Client.SetApiKey("wrong")
+
+	_, err := server.Client.GetUser(context.Background(), "laura")
+
+	var apiErr *kik.APIError
+	if !errors.As(err, &apiErr) || !apiErr.IsUnauthorized() {
+		t.Errorf("GetUser(laura) = %v; want an unauthorized *kik.APIError", err)
+	}
+}
+
+func TestServer_Webhook(t *testing.T) {
+	server := kiktest.NewServer(t)
+	defer server.Close()
+
+	var got []kik.Receive
+	handler := kik.NewWebhookHandler(server.Client, func(ctx context.Context, m kik.Receive) error {
+		got = append(got, m)
+		return nil
+	})
+
+	rec := server.Webhook(handler,
+		kik.TextMessageReceive{ReceiveMessage: kik.ReceiveMessage{Type: "text", From: "laura"}, Body: "Hi"},
+		kik.StartChattingReceive{ReceiveMessage: kik.ReceiveMessage{Type: "start-chatting", From: "laura"}},
+	)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("Webhook() status = %d; want %d", rec.Code, http.StatusOK)
+	}
+	if len(got) != 2 || got[0].(*kik.TextMessageReceive).Body != "Hi" {
+		t.Errorf("handled %v; want the text and start-chatting message", got)
+	}
+}
@@ -4,6 +4,7 @@
 package integration
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -52,7 +53,7 @@ func init() {
 
 func TestGetUser_HappyPath(t *testing.T) {
 
-	_, err := kikClient.GetUser(testUserName)
+	_, err := kikClient.GetUser(context.Background(), testUserName)
 	if err != nil {
 		t.Errorf("Could not find user: %v ", err)
 	}
@@ -60,7 +61,7 @@ func TestGetUser_HappyPath(t *testing.T) {
 
 func TestSendMessage_HappyPath(t *testing.T) {
 
-	err := kikClient.SendMessage(allMessageTypesTestData)
+	err := kikClient.SendMessage(context.Background(), allMessageTypesTestData)
 	if err != nil {
 		t.Errorf("Error while trying to send a message. %v.", err)
 	}
@@ -68,7 +69,7 @@ func TestSendMessage_HappyPath(t *testing.T) {
 
 func TestBroadcastMessage_HappyPath(t *testing.T) {
 
-	err := kikClient.BroadcastMessage(allMessageTypesTestData)
+	err := kikClient.BroadcastMessage(context.Background(), allMessageTypesTestData)
 	if err != nil {
 		t.Errorf("Error while trying to broadcast a message. %v.", err)
 	}
@@ -78,7 +79,7 @@ func TestBroadcastMessage_HappyPath(t *testing.T) {
 func TestConfig_HappyPath(t *testing.T) {
 	keyboard := &kik.SuggestedResponseKeyboard{
 		Type: "suggested",
-		Responses: []interface{}{
+		Responses: []kik.SuggestedResponse{
 			kik.KeyboardTextResponse{
 				Type: "text",
 				Body: "StaticKeyboardTest",
@@ -86,7 +87,7 @@ func TestConfig_HappyPath(t *testing.T) {
 		},
 	}
 	wantConfig := &kik.Configuration{
-		Webhook: "http://example.com",
+		Webhook: "https://example.com",
 		Features: &kik.Features{
 			ManuallySendReadReceipts: false,
 			ReceiveReadReceipts:      false,
@@ -95,11 +96,11 @@ func TestConfig_HappyPath(t *testing.T) {
 		},
 		StaticKeyboard: keyboard,
 	}
-	err := kikClient.SetConfiguration(wantConfig)
+	err := kikClient.SetConfiguration(context.Background(), wantConfig)
 	if err != nil {
 		t.Errorf("Error while trying to set configuration. %v.", err)
 	}
-	gotConfig, err := kikClient.GetConfiguration()
+	gotConfig, err := kikClient.GetConfiguration(context.Background())
 	if err != nil {
 		t.Errorf("Error while trying to get configuration. %v.", err)
 	}
@@ -115,7 +116,7 @@ func TestCreateCode_HappyPath(t *testing.T) {
 	scanCodeData := &kik.ScanData{
 		Data: "Kik Scan Code Example Data!",
 	}
-	code, err := kikClient.CreateCode(scanCodeData)
+	code, err := kikClient.CreateCode(context.Background(), scanCodeData)
 	if err != nil {
 		t.Errorf("Error while trying create a Kik scan code. %v.", err)
 	}
@@ -145,7 +146,7 @@ var testUserName = "rmdkelly"
 // Contains an example of all the keyboard response types.
 var allKeyboardTypesTestData = []kik.SuggestedResponseKeyboard{
 	{Type: "suggested",
-		Responses: []interface{}{
+		Responses: []kik.SuggestedResponse{
 			kik.KeyboardPictureResponse{
 				Type:     "picture",
 				PicUrl:   "https://i.imgur.com/8rqLdgy.png",